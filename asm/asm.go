@@ -0,0 +1,987 @@
+// Package asm assembles Chip-8 programs written in the assembly language of Octo.
+//
+// The assembler supports labels, :const, :alias, :calc, :macro, :byte, :org and :call directives,
+// if ... then and if ... begin ... else ... end conditionals, loop ... while ... again loops,
+// all Chip-8, SUPER-CHIP and XO-CHIP instructions, and sprite data written as plain numbers.
+// A label which is used as a statement calls the subroutine at that label.
+//
+// Instructions are encoded with chip8.Encode, which shares its opcode table with the interpreter.
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// programStart is the address at which Chip-8 programs are loaded.
+const programStart = 0x200
+
+// maxExpansions limits the number of macro expansions in a program, to catch macros which expand themselves.
+const maxExpansions = 1 << 16
+
+// Error is an error in the source of a program.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+type token struct {
+	text string
+	line int
+}
+
+// tokenize splits src into whitespace separated tokens, dropping comments which start with #.
+func tokenize(src string) []token {
+	var tokens []token
+	for i, line := range strings.Split(src, "\n") {
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
+		for _, text := range strings.Fields(line) {
+			tokens = append(tokens, token{text: text, line: i + 1})
+		}
+	}
+	return tokens
+}
+
+type macro struct {
+	args []string
+	body []token
+}
+
+// fixup is an operand which refers to a label which had not been defined when the instruction was assembled.
+type fixup struct {
+	addr int
+	op   chip8.Opcode
+	name string
+	line int
+}
+
+// loop is a loop which has not reached its again yet.
+type loop struct {
+	start int
+
+	// whiles are the addresses of the jumps out of the loop
+	whiles []int
+}
+
+type assembler struct {
+	tokens []token
+	pos    int
+	line   int
+
+	rom  []byte
+	here int
+
+	labels  map[string]int
+	consts  map[string]float64
+	aliases map[string]uint8
+	macros  map[string]macro
+
+	fixups     []fixup
+	loops      []loop
+	ifs        []int
+	expansions int
+}
+
+// Assemble assembles Octo source into a program which can be loaded at 0x200.
+//
+// If the program has a main label which is not at the start of the program, it starts with a jump to main.
+func Assemble(src string) ([]byte, error) {
+	a := &assembler{
+		tokens:  tokenize(src),
+		here:    programStart,
+		labels:  make(map[string]int),
+		consts:  make(map[string]float64),
+		aliases: make(map[string]uint8),
+		macros:  make(map[string]macro),
+	}
+	if a.hasMain() {
+		if err := a.emitAddr(chip8.OpJP_1nnn, "main"); err != nil {
+			return nil, err
+		}
+	}
+	for a.pos < len(a.tokens) {
+		if err := a.statement(); err != nil {
+			return nil, err
+		}
+	}
+	if len(a.loops) > 0 {
+		return nil, a.errorf("loop without again")
+	}
+	if len(a.ifs) > 0 {
+		return nil, a.errorf("begin without end")
+	}
+	for _, f := range a.fixups {
+		addr, ok := a.labels[f.name]
+		if !ok {
+			return nil, &Error{Line: f.line, Msg: fmt.Sprintf("undefined label %q", f.name)}
+		}
+		a.line = f.line
+		if err := a.patch(f.addr, f.op, addr); err != nil {
+			return nil, err
+		}
+	}
+	return a.rom, nil
+}
+
+// hasMain reports whether the program defines a main label anywhere but at its very start.
+func (a *assembler) hasMain() bool {
+	for i := 0; i+1 < len(a.tokens); i++ {
+		if a.tokens[i].text == ":" && a.tokens[i+1].text == "main" {
+			return i > 0
+		}
+	}
+	return false
+}
+
+func (a *assembler) errorf(format string, args ...interface{}) error {
+	return &Error{Line: a.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next returns the next token, or an error at the end of the program.
+func (a *assembler) next() (string, error) {
+	if a.pos >= len(a.tokens) {
+		return "", a.errorf("unexpected end of program")
+	}
+	t := a.tokens[a.pos]
+	a.pos++
+	a.line = t.line
+	return t.text, nil
+}
+
+// peek returns the next token without consuming it, or "" at the end of the program.
+func (a *assembler) peek() string {
+	if a.pos >= len(a.tokens) {
+		return ""
+	}
+	return a.tokens[a.pos].text
+}
+
+func (a *assembler) expect(want string) error {
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+	if t != want {
+		return a.errorf("expected %q, found %q", want, t)
+	}
+	return nil
+}
+
+func (a *assembler) emit(b byte) error {
+	if a.here < programStart || a.here > 0xFFFF {
+		return a.errorf("address 0x%X is outside program memory", a.here)
+	}
+	i := a.here - programStart
+	for len(a.rom) <= i {
+		a.rom = append(a.rom, 0)
+	}
+	a.rom[i] = b
+	a.here++
+	return nil
+}
+
+func (a *assembler) emitWord(w uint16) error {
+	if err := a.emit(uint8(w >> 8)); err != nil {
+		return err
+	}
+	return a.emit(uint8(w))
+}
+
+// emitOp emits an instruction with the operands x, y and imm.
+func (a *assembler) emitOp(op chip8.Opcode, x, y uint8, imm uint16) error {
+	w, err := chip8.Encode(op, x, y, imm)
+	if err != nil {
+		return a.errorf("%v", err)
+	}
+	return a.emitWord(w)
+}
+
+// emitAddr emits an instruction whose only operand is the address given by the token t.
+// If t is a label which has not been defined yet, the address is filled in once the program has been assembled.
+func (a *assembler) emitAddr(op chip8.Opcode, t string) error {
+	addr := a.here
+	if _, isConst := a.consts[t]; !isConst && !isNumber(t) {
+		if v, ok := a.labels[t]; ok {
+			return a.patchNew(op, v)
+		}
+		if !isIdentifier(t) {
+			return a.errorf("invalid address %q", t)
+		}
+		a.fixups = append(a.fixups, fixup{addr: addr, op: op, name: t, line: a.line})
+		return a.placeholder(op)
+	}
+	v, err := a.value(t)
+	if err != nil {
+		return err
+	}
+	return a.patchNew(op, int(v))
+}
+
+// placeholder reserves space for an instruction with an address operand, which is written later by patch.
+func (a *assembler) placeholder(op chip8.Opcode) error {
+	if err := a.emitWord(0); err != nil {
+		return err
+	}
+	if op == chip8.OpLD_F000 {
+		return a.emitWord(0)
+	}
+	return nil
+}
+
+// patchNew emits an instruction with an address operand.
+func (a *assembler) patchNew(op chip8.Opcode, addr int) error {
+	at := a.here
+	if err := a.placeholder(op); err != nil {
+		return err
+	}
+	return a.patch(at, op, addr)
+}
+
+// patch writes an instruction with an address operand over the placeholder at at.
+func (a *assembler) patch(at int, op chip8.Opcode, addr int) error {
+	if addr < 0 || addr > 0xFFFF {
+		return a.errorf("address 0x%X is outside memory", addr)
+	}
+	var imm uint16
+	if op != chip8.OpLD_F000 {
+		imm = uint16(addr)
+	}
+	w, err := chip8.Encode(op, 0, 0, imm)
+	if err != nil {
+		return a.errorf("%v", err)
+	}
+	i := at - programStart
+	a.rom[i], a.rom[i+1] = uint8(w>>8), uint8(w)
+	if op == chip8.OpLD_F000 {
+		a.rom[i+2], a.rom[i+3] = uint8(addr>>8), uint8(addr)
+	}
+	return nil
+}
+
+// statement assembles a single statement.
+func (a *assembler) statement() error {
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+	if op, ok := simpleOps[t]; ok {
+		return a.emitOp(op, 0, 0, 0)
+	}
+	if _, ok := a.register(t); ok {
+		return a.registerStatement(t)
+	}
+	switch t {
+	case ":":
+		name, err := a.next()
+		if err != nil {
+			return err
+		}
+		if err := a.define(name); err != nil {
+			return err
+		}
+		a.labels[name] = a.here
+		return nil
+	case ":const":
+		name, err := a.next()
+		if err != nil {
+			return err
+		}
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		v, err := a.value(t)
+		if err != nil {
+			return err
+		}
+		if err := a.define(name); err != nil {
+			return err
+		}
+		a.consts[name] = v
+		return nil
+	case ":alias":
+		name, err := a.next()
+		if err != nil {
+			return err
+		}
+		x, err := a.nextRegister()
+		if err != nil {
+			return err
+		}
+		if err := a.define(name); err != nil {
+			return err
+		}
+		a.aliases[name] = x
+		return nil
+	case ":calc":
+		name, err := a.next()
+		if err != nil {
+			return err
+		}
+		v, err := a.calc()
+		if err != nil {
+			return err
+		}
+		if _, ok := a.consts[name]; !ok {
+			if err := a.define(name); err != nil {
+				return err
+			}
+		}
+		a.consts[name] = v
+		return nil
+	case ":macro":
+		return a.defineMacro()
+	case ":byte":
+		var v float64
+		if a.peek() == "{" {
+			v, err = a.calc()
+		} else {
+			v, err = a.nextValue()
+		}
+		if err != nil {
+			return err
+		}
+		return a.emitByte(v)
+	case ":org":
+		v, err := a.nextValue()
+		if err != nil {
+			return err
+		}
+		a.here = int(v)
+		return nil
+	case ":call":
+		return a.nextAddr(chip8.OpCALL_2nnn)
+	case "jump":
+		return a.nextAddr(chip8.OpJP_1nnn)
+	case "jump0":
+		return a.nextAddr(chip8.OpJP_Bnnn)
+	case "native":
+		return a.nextAddr(chip8.OpSYS_0nnn)
+	case "scroll-down", "scroll-up":
+		n, err := a.nextValue()
+		if err != nil {
+			return err
+		}
+		op := chip8.OpSCD_00Cn
+		if t == "scroll-up" {
+			op = chip8.OpSCU_00Dn
+		}
+		return a.emitOp(op, 0, 0, uint16(n))
+	case "bcd", "saveflags", "loadflags":
+		x, err := a.nextRegister()
+		if err != nil {
+			return err
+		}
+		return a.emitOp(registerOps[t], x, 0, 0)
+	case "save", "load":
+		x, err := a.nextRegister()
+		if err != nil {
+			return err
+		}
+		if a.peek() != "-" {
+			return a.emitOp(registerOps[t], x, 0, 0)
+		}
+		a.pos++
+		y, err := a.nextRegister()
+		if err != nil {
+			return err
+		}
+		op := chip8.OpSAVE_5xy2
+		if t == "load" {
+			op = chip8.OpLOAD_5xy3
+		}
+		return a.emitOp(op, x, y, 0)
+	case "sprite":
+		x, err := a.nextRegister()
+		if err != nil {
+			return err
+		}
+		y, err := a.nextRegister()
+		if err != nil {
+			return err
+		}
+		n, err := a.nextValue()
+		if err != nil {
+			return err
+		}
+		return a.emitOp(chip8.OpDRW_Dxyn, x, y, uint16(n))
+	case "plane":
+		n, err := a.nextValue()
+		if err != nil {
+			return err
+		}
+		if n < 0 || n > 3 {
+			return a.errorf("plane %v is not between 0 and 3", n)
+		}
+		return a.emitOp(chip8.OpPLANE_Fn01, uint8(n), 0, 0)
+	case "delay", "buzzer", "pitch":
+		if err := a.expect(":="); err != nil {
+			return err
+		}
+		x, err := a.nextRegister()
+		if err != nil {
+			return err
+		}
+		return a.emitOp(registerOps[t], x, 0, 0)
+	case "i":
+		return a.indexStatement()
+	case "if":
+		return a.ifStatement()
+	case "else":
+		if len(a.ifs) == 0 {
+			return a.errorf("else without begin")
+		}
+		at := a.ifs[len(a.ifs)-1]
+		a.ifs[len(a.ifs)-1] = a.here
+		if err := a.emitWord(0); err != nil {
+			return err
+		}
+		return a.patch(at, chip8.OpJP_1nnn, a.here)
+	case "end":
+		if len(a.ifs) == 0 {
+			return a.errorf("end without begin")
+		}
+		at := a.ifs[len(a.ifs)-1]
+		a.ifs = a.ifs[:len(a.ifs)-1]
+		return a.patch(at, chip8.OpJP_1nnn, a.here)
+	case "loop":
+		a.loops = append(a.loops, loop{start: a.here})
+		return nil
+	case "while":
+		if len(a.loops) == 0 {
+			return a.errorf("while outside a loop")
+		}
+		// leave the loop unless the condition holds
+		if err := a.condition(true); err != nil {
+			return err
+		}
+		l := &a.loops[len(a.loops)-1]
+		l.whiles = append(l.whiles, a.here)
+		return a.emitWord(0)
+	case "again":
+		if len(a.loops) == 0 {
+			return a.errorf("again without loop")
+		}
+		l := a.loops[len(a.loops)-1]
+		a.loops = a.loops[:len(a.loops)-1]
+		if err := a.patchNew(chip8.OpJP_1nnn, l.start); err != nil {
+			return err
+		}
+		for _, at := range l.whiles {
+			if err := a.patch(at, chip8.OpJP_1nnn, a.here); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if m, ok := a.macros[t]; ok {
+		return a.expand(m)
+	}
+	if _, isConst := a.consts[t]; isConst || isNumber(t) {
+		v, err := a.value(t)
+		if err != nil {
+			return err
+		}
+		return a.emitByte(v)
+	}
+	if isIdentifier(t) && !keywords[t] {
+		return a.emitAddr(chip8.OpCALL_2nnn, t)
+	}
+	return a.errorf("unexpected %q", t)
+}
+
+// simpleOps are the instructions which are a single keyword.
+var simpleOps = map[string]chip8.Opcode{
+	"clear":        chip8.OpCLS_00E0,
+	"return":       chip8.OpRET_00EE,
+	";":            chip8.OpRET_00EE,
+	"exit":         chip8.OpEXIT_00FD,
+	"lores":        chip8.OpLOW_00FE,
+	"hires":        chip8.OpHIGH_00FF,
+	"scroll-right": chip8.OpSCR_00FB,
+	"scroll-left":  chip8.OpSCL_00FC,
+	"audio":        chip8.OpAUDIO_F002,
+}
+
+// registerOps are the instructions whose only operand is a register.
+var registerOps = map[string]chip8.Opcode{
+	"bcd":       chip8.OpLD_Fx33,
+	"save":      chip8.OpLD_Fx55,
+	"load":      chip8.OpLD_Fx65,
+	"saveflags": chip8.OpLD_Fx75,
+	"loadflags": chip8.OpLD_Fx85,
+	"delay":     chip8.OpLD_Fx15,
+	"buzzer":    chip8.OpLD_Fx18,
+	"pitch":     chip8.OpPITCH_Fx3A,
+}
+
+// keywords are the names which cannot be used for labels, constants, aliases or macros.
+var keywords = map[string]bool{
+	"i": true, "if": true, "then": true, "begin": true, "else": true, "end": true,
+	"loop": true, "while": true, "again": true, "key": true, "-key": true,
+	"jump": true, "jump0": true, "native": true, "sprite": true, "plane": true,
+	"random": true, "hex": true, "bighex": true, "long": true,
+	"scroll-down": true, "scroll-up": true,
+}
+
+func init() {
+	for k := range simpleOps {
+		keywords[k] = true
+	}
+	for k := range registerOps {
+		keywords[k] = true
+	}
+}
+
+// arithmeticOps are the instructions for the operators between two registers.
+var arithmeticOps = map[string]chip8.Opcode{
+	":=":  chip8.OpLD_8xy0,
+	"|=":  chip8.OpOR_8xy1,
+	"&=":  chip8.OpAND_8xy2,
+	"^=":  chip8.OpXOR_8xy3,
+	"+=":  chip8.OpADD_8xy4,
+	"-=":  chip8.OpSUB_8xy5,
+	">>=": chip8.OpSHR_8xy6,
+	"=-":  chip8.OpSUBN_8xy7,
+	"<<=": chip8.OpSHL_8xyE,
+}
+
+// registerStatement assembles a statement which assigns to the register t.
+func (a *assembler) registerStatement(t string) error {
+	x, _ := a.register(t)
+	op, err := a.next()
+	if err != nil {
+		return err
+	}
+	rhs, err := a.next()
+	if err != nil {
+		return err
+	}
+	if y, ok := a.register(rhs); ok {
+		aop, ok := arithmeticOps[op]
+		if !ok {
+			return a.errorf("unknown operator %q", op)
+		}
+		return a.emitOp(aop, x, y, 0)
+	}
+	switch op {
+	case ":=":
+		switch rhs {
+		case "random":
+			kk, err := a.nextByte()
+			if err != nil {
+				return err
+			}
+			return a.emitOp(chip8.OpRND_Cxkk, x, 0, uint16(kk))
+		case "delay":
+			return a.emitOp(chip8.OpLD_Fx07, x, 0, 0)
+		case "key":
+			return a.emitOp(chip8.OpLD_Fx0A, x, 0, 0)
+		}
+		kk, err := a.byteValue(rhs)
+		if err != nil {
+			return err
+		}
+		return a.emitOp(chip8.OpLD_6xkk, x, 0, uint16(kk))
+	case "+=", "-=":
+		kk, err := a.byteValue(rhs)
+		if err != nil {
+			return err
+		}
+		if op == "-=" {
+			kk = -kk
+		}
+		return a.emitOp(chip8.OpADD_7xkk, x, 0, uint16(kk))
+	}
+	return a.errorf("operator %q needs a register", op)
+}
+
+// indexStatement assembles a statement which assigns to I.
+func (a *assembler) indexStatement() error {
+	op, err := a.next()
+	if err != nil {
+		return err
+	}
+	switch op {
+	case ":=":
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case "hex", "bighex":
+			x, err := a.nextRegister()
+			if err != nil {
+				return err
+			}
+			if t == "hex" {
+				return a.emitOp(chip8.OpLD_Fx29, x, 0, 0)
+			}
+			return a.emitOp(chip8.OpLD_Fx30, x, 0, 0)
+		case "long":
+			return a.nextAddr(chip8.OpLD_F000)
+		}
+		return a.emitAddr(chip8.OpLD_Annn, t)
+	case "+=":
+		x, err := a.nextRegister()
+		if err != nil {
+			return err
+		}
+		return a.emitOp(chip8.OpADD_Fx1E, x, 0, 0)
+	}
+	return a.errorf("unknown operator %q", op)
+}
+
+// ifStatement assembles a conditional, either if ... then, which only applies to the next statement,
+// or if ... begin, which applies to everything up to the matching else or end.
+func (a *assembler) ifStatement() error {
+	lookahead := a.pos
+	for lookahead < len(a.tokens) && a.tokens[lookahead].text != "then" && a.tokens[lookahead].text != "begin" {
+		lookahead++
+	}
+	if lookahead == len(a.tokens) {
+		return a.errorf("if without then or begin")
+	}
+	begin := a.tokens[lookahead].text == "begin"
+	// with begin, jump over the block unless the condition holds
+	if err := a.condition(begin); err != nil {
+		return err
+	}
+	if _, err := a.next(); err != nil {
+		return err
+	}
+	if begin {
+		a.ifs = append(a.ifs, a.here)
+		return a.emitWord(0)
+	}
+	return nil
+}
+
+// condition assembles a condition, which skips the next instruction unless the condition holds.
+// If negate is true, the next instruction is skipped if the condition holds instead.
+//
+// Comparisons other than == and != are computed with subtraction and overwrite VF.
+func (a *assembler) condition(negate bool) error {
+	x, err := a.nextRegister()
+	if err != nil {
+		return err
+	}
+	op, err := a.next()
+	if err != nil {
+		return err
+	}
+	if op == "key" || op == "-key" {
+		// skip if the key is not pressed
+		if (op == "key") != negate {
+			return a.emitOp(chip8.OpSKNP_ExA1, x, 0, 0)
+		}
+		return a.emitOp(chip8.OpSKP_Ex9E, x, 0, 0)
+	}
+	if negate {
+		op = negations[op]
+	}
+	rhs, err := a.next()
+	if err != nil {
+		return err
+	}
+	y, isRegister := a.register(rhs)
+	var kk uint8
+	if !isRegister {
+		if kk, err = a.byteValue(rhs); err != nil {
+			return err
+		}
+	}
+	switch op {
+	case "==", "!=":
+		eq := op == "=="
+		switch {
+		case isRegister && eq:
+			return a.emitOp(chip8.OpSNE_9xy0, x, y, 0)
+		case isRegister:
+			return a.emitOp(chip8.OpSE_5xy0, x, y, 0)
+		case eq:
+			return a.emitOp(chip8.OpSNE_4xkk, x, 0, uint16(kk))
+		default:
+			return a.emitOp(chip8.OpSE_3xkk, x, 0, uint16(kk))
+		}
+	case "<", ">=", ">", "<=":
+		// VF is set to the no borrow flag of a subtraction
+		const vf = 0xF
+		var err error
+		if op == "<" || op == ">=" {
+			// VF = Vx >= rhs
+			if isRegister {
+				err = a.emitOps(chip8.OpLD_8xy0, vf, x, chip8.OpSUB_8xy5, vf, y)
+			} else {
+				err = a.emitLoadSub(kk, chip8.OpSUBN_8xy7, x)
+			}
+		} else {
+			// VF = rhs >= Vx
+			if isRegister {
+				err = a.emitOps(chip8.OpLD_8xy0, vf, x, chip8.OpSUBN_8xy7, vf, y)
+			} else {
+				err = a.emitLoadSub(kk, chip8.OpSUB_8xy5, x)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if op == "<" || op == ">" {
+			return a.emitOp(chip8.OpSNE_4xkk, vf, 0, 0)
+		}
+		return a.emitOp(chip8.OpSE_3xkk, vf, 0, 0)
+	}
+	return a.errorf("unknown comparison %q", op)
+}
+
+// negations maps each comparison to its opposite.
+var negations = map[string]string{
+	"==": "!=",
+	"!=": "==",
+	"<":  ">=",
+	">=": "<",
+	">":  "<=",
+	"<=": ">",
+}
+
+// emitOps emits two instructions between registers.
+func (a *assembler) emitOps(op1 chip8.Opcode, x1, y1 uint8, op2 chip8.Opcode, x2, y2 uint8) error {
+	if err := a.emitOp(op1, x1, y1, 0); err != nil {
+		return err
+	}
+	return a.emitOp(op2, x2, y2, 0)
+}
+
+// emitLoadSub loads kk into VF and then subtracts with op between VF and Vx.
+func (a *assembler) emitLoadSub(kk uint8, op chip8.Opcode, x uint8) error {
+	if err := a.emitOp(chip8.OpLD_6xkk, 0xF, 0, uint16(kk)); err != nil {
+		return err
+	}
+	return a.emitOp(op, 0xF, x, 0)
+}
+
+// defineMacro reads a macro definition: its name, its arguments and its body in braces.
+func (a *assembler) defineMacro() error {
+	name, err := a.next()
+	if err != nil {
+		return err
+	}
+	if err := a.define(name); err != nil {
+		return err
+	}
+	var m macro
+	for {
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		if t == "{" {
+			break
+		}
+		m.args = append(m.args, t)
+	}
+	for depth := 1; ; {
+		if a.pos >= len(a.tokens) {
+			return a.errorf("macro %s is missing }", name)
+		}
+		t := a.tokens[a.pos]
+		a.pos++
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+		m.body = append(m.body, t)
+	}
+	a.macros[name] = m
+	return nil
+}
+
+// expand replaces a macro invocation with the body of the macro.
+func (a *assembler) expand(m macro) error {
+	a.expansions++
+	if a.expansions > maxExpansions {
+		return a.errorf("too many macro expansions")
+	}
+	args := make(map[string]string)
+	for _, name := range m.args {
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		args[name] = t
+	}
+	body := make([]token, len(m.body))
+	for i, t := range m.body {
+		if arg, ok := args[t.text]; ok {
+			t.text = arg
+		}
+		body[i] = t
+	}
+	a.tokens = append(body, a.tokens[a.pos:]...)
+	a.pos = 0
+	return nil
+}
+
+// define checks that name can be given to a new label, constant, alias or macro.
+func (a *assembler) define(name string) error {
+	if !isIdentifier(name) || keywords[name] {
+		return a.errorf("invalid name %q", name)
+	}
+	if _, ok := a.register(name); ok {
+		return a.errorf("%q is already a register", name)
+	}
+	_, isLabel := a.labels[name]
+	_, isConst := a.consts[name]
+	_, isMacro := a.macros[name]
+	if isLabel || isConst || isMacro {
+		return a.errorf("%q is already defined", name)
+	}
+	return nil
+}
+
+// register returns the register named by t, which is either v0 to vF or an alias.
+func (a *assembler) register(t string) (uint8, bool) {
+	if x, ok := a.aliases[t]; ok {
+		return x, true
+	}
+	if len(t) != 2 || t[0] != 'v' && t[0] != 'V' {
+		return 0, false
+	}
+	x, err := strconv.ParseUint(t[1:], 16, 4)
+	if err != nil {
+		return 0, false
+	}
+	return uint8(x), true
+}
+
+func (a *assembler) nextRegister() (uint8, error) {
+	t, err := a.next()
+	if err != nil {
+		return 0, err
+	}
+	x, ok := a.register(t)
+	if !ok {
+		return 0, a.errorf("expected a register, found %q", t)
+	}
+	return x, nil
+}
+
+func (a *assembler) nextAddr(op chip8.Opcode) error {
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+	return a.emitAddr(op, t)
+}
+
+// value returns the value of a number, constant or label which has already been defined.
+func (a *assembler) value(t string) (float64, error) {
+	if v, ok := a.consts[t]; ok {
+		return v, nil
+	}
+	if v, ok := a.labels[t]; ok {
+		return float64(v), nil
+	}
+	n, ok := parseNumber(t)
+	if !ok {
+		return 0, a.errorf("expected a number, found %q", t)
+	}
+	return float64(n), nil
+}
+
+func (a *assembler) nextValue() (float64, error) {
+	t, err := a.next()
+	if err != nil {
+		return 0, err
+	}
+	return a.value(t)
+}
+
+// byteValue returns the value of t as a byte. Negative values down to -128 are allowed.
+func (a *assembler) byteValue(t string) (uint8, error) {
+	v, err := a.value(t)
+	if err != nil {
+		return 0, err
+	}
+	return a.toByte(v)
+}
+
+func (a *assembler) nextByte() (uint8, error) {
+	t, err := a.next()
+	if err != nil {
+		return 0, err
+	}
+	return a.byteValue(t)
+}
+
+func (a *assembler) toByte(v float64) (uint8, error) {
+	n := int(v)
+	if n < -128 || n > 255 {
+		return 0, a.errorf("value %d does not fit in a byte", n)
+	}
+	return uint8(n), nil
+}
+
+func (a *assembler) emitByte(v float64) error {
+	b, err := a.toByte(v)
+	if err != nil {
+		return err
+	}
+	return a.emit(b)
+}
+
+// parseNumber parses a decimal, hexadecimal (0x) or binary (0b) integer, which may be negative.
+func parseNumber(t string) (int64, bool) {
+	s := t
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	base := 10
+	switch {
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X"):
+		base, s = 16, s[2:]
+	case strings.HasPrefix(s, "0b") || strings.HasPrefix(s, "0B"):
+		base, s = 2, s[2:]
+	}
+	n, err := strconv.ParseInt(s, base, 64)
+	if err != nil || s == "" || s[0] == '+' || s[0] == '-' {
+		return 0, false
+	}
+	if neg {
+		n = -n
+	}
+	return n, true
+}
+
+func isNumber(t string) bool {
+	_, ok := parseNumber(t)
+	return ok
+}
+
+// isIdentifier reports whether t can be the name of a label, constant, alias or macro.
+func isIdentifier(t string) bool {
+	if t == "" || t[0] == '-' || t[0] >= '0' && t[0] <= '9' {
+		return false
+	}
+	for _, r := range t {
+		if !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,265 @@
+package asm
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []byte
+	}{
+		{
+			name: "simple instructions",
+			src:  "clear return ; exit lores hires scroll-right scroll-left audio",
+			want: []byte{0x00, 0xE0, 0x00, 0xEE, 0x00, 0xEE, 0x00, 0xFD, 0x00, 0xFE, 0x00, 0xFF, 0x00, 0xFB, 0x00, 0xFC, 0xF0, 0x02},
+		},
+		{
+			name: "registers",
+			src: `
+v3 := 0x1F
+v3 := v4
+v3 += 2
+v3 -= 2
+v3 += v4
+v3 -= v4
+v3 =- v4
+v3 |= v4
+v3 &= v4
+v3 ^= v4
+v3 >>= v4
+v3 <<= v4
+v3 := random 0xFF
+v3 := delay
+v3 := key
+vA := -1`,
+			want: []byte{
+				0x63, 0x1F, 0x83, 0x40, 0x73, 0x02, 0x73, 0xFE, 0x83, 0x44, 0x83, 0x45, 0x83, 0x47, 0x83, 0x41,
+				0x83, 0x42, 0x83, 0x43, 0x83, 0x46, 0x83, 0x4E, 0xC3, 0xFF, 0xF3, 0x07, 0xF3, 0x0A, 0x6A, 0xFF,
+			},
+		},
+		{
+			name: "index and memory",
+			src: `
+i := 0x300
+i += v1
+i := hex v1
+i := bighex v1
+i := long 0x1234
+bcd v1
+save v1
+load v1
+save v1 - v2
+load v1 - v2
+saveflags v1
+loadflags v1`,
+			want: []byte{
+				0xA3, 0x00, 0xF1, 0x1E, 0xF1, 0x29, 0xF1, 0x30, 0xF0, 0x00, 0x12, 0x34, 0xF1, 0x33,
+				0xF1, 0x55, 0xF1, 0x65, 0x51, 0x22, 0x51, 0x23, 0xF1, 0x75, 0xF1, 0x85,
+			},
+		},
+		{
+			name: "timers, sprites and planes",
+			src:  "delay := v1 buzzer := v1 pitch := v1 sprite v0 v1 5 plane 3 scroll-down 4 scroll-up 2",
+			want: []byte{0xF1, 0x15, 0xF1, 0x18, 0xF1, 0x3A, 0xD0, 0x15, 0xF3, 0x01, 0x00, 0xC4, 0x00, 0xD2},
+		},
+		{
+			name: "labels and jumps",
+			src: `
+: main
+	sub
+	jump done
+	jump0 table
+	native 0x123
+	:call sub
+: sub
+	return
+: table
+: done`,
+			want: []byte{0x22, 0x0A, 0x12, 0x0C, 0xB2, 0x0C, 0x01, 0x23, 0x22, 0x0A, 0x00, 0xEE},
+		},
+		{
+			name: "jump to main",
+			src: `
+: data 0xFF 0x81
+: main
+	i := data`,
+			want: []byte{0x12, 0x04, 0xFF, 0x81, 0xA2, 0x02},
+		},
+		{
+			name: "const and alias",
+			src: `
+:const speed 3
+:alias x v5
+x := speed
+x += speed`,
+			want: []byte{0x65, 0x03, 0x75, 0x03},
+		},
+		{
+			name: "if then",
+			src: `
+if v1 == 2 then v0 := 1
+if v1 != 2 then v0 := 1
+if v1 == v2 then v0 := 1
+if v1 != v2 then v0 := 1
+if v1 key then v0 := 1
+if v1 -key then v0 := 1`,
+			want: []byte{
+				0x41, 0x02, 0x60, 0x01, 0x31, 0x02, 0x60, 0x01, 0x91, 0x20, 0x60, 0x01,
+				0x51, 0x20, 0x60, 0x01, 0xE1, 0xA1, 0x60, 0x01, 0xE1, 0x9E, 0x60, 0x01,
+			},
+		},
+		{
+			name: "comparisons",
+			src: `
+if v1 < v2 then v0 := 1
+if v1 > 5 then v0 := 1`,
+			want: []byte{
+				0x8F, 0x10, 0x8F, 0x25, 0x4F, 0x00, 0x60, 0x01,
+				0x6F, 0x05, 0x8F, 0x15, 0x4F, 0x00, 0x60, 0x01,
+			},
+		},
+		{
+			name: "if begin else end",
+			src: `
+if v1 == 2 begin
+	v0 := 1
+else
+	v0 := 2
+end`,
+			want: []byte{0x31, 0x02, 0x12, 0x08, 0x60, 0x01, 0x12, 0x0A, 0x60, 0x02},
+		},
+		{
+			name: "loop while again",
+			src: `
+loop
+	v0 += 1
+	while v0 != 10
+again`,
+			want: []byte{0x70, 0x01, 0x40, 0x0A, 0x12, 0x08, 0x12, 0x00},
+		},
+		{
+			name: "macro",
+			src: `
+:macro move reg amount { reg += amount }
+move v1 2
+move v2 3`,
+			want: []byte{0x71, 0x02, 0x72, 0x03},
+		},
+		{
+			name: "calc",
+			src: `
+:const width 8
+:calc half { width / 2 }
+:calc sum { 1 - 2 + 3 }
+v0 := half
+:byte { ( 1 - 2 ) + 3 }
+:byte { sum + 4 }`,
+			want: []byte{0x60, 0x04, 0x02, 0x00},
+		},
+		{
+			name: "sprite data",
+			src: `
+: sprite-data
+	0b11110000 0x90 144 0xF0 # the digit 0
+	:byte 0x90`,
+			want: []byte{0xF0, 0x90, 0x90, 0xF0, 0x90},
+		},
+		{
+			name: "org",
+			src:  ":org 0x204 0x12",
+			want: []byte{0x00, 0x00, 0x00, 0x00, 0x12},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Assemble(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Assemble() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAssemble_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "undefined label",
+			src:  "clear\njump nowhere",
+			want: `line 2: undefined label "nowhere"`,
+		},
+		{
+			name: "byte out of range",
+			src:  "v0 := 256",
+			want: "line 1: value 256 does not fit in a byte",
+		},
+		{
+			name: "address out of range",
+			src:  "jump 0x1000",
+			want: "line 1: chip8: operand 0x1000 is larger than 0xFFF",
+		},
+		{
+			name: "redefined label",
+			src:  ": a\n: a",
+			want: `line 2: "a" is already defined`,
+		},
+		{
+			name: "unterminated loop",
+			src:  "loop clear",
+			want: "line 1: loop without again",
+		},
+		{
+			name: "recursive macro",
+			src:  ":macro forever { forever }\nforever",
+			want: "line 1: too many macro expansions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Assemble(tt.src)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("want = %q, got = %q", tt.want, err.Error())
+			}
+		})
+	}
+}
+
+// TestAssemble_load checks that an assembled program runs in the interpreter.
+func TestAssemble_load(t *testing.T) {
+	prog, err := Assemble(`
+: main
+	v0 := 0
+	loop
+		v0 += 3
+		while v0 < 30
+	again
+	exit`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ip := chip8.New(nil, nil, chip8.QuirksXOCHIP)
+	ip.Load(prog)
+	for i := 0; i < 1000 && !ip.Exited(); i++ {
+		if err := ip.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := ip.Registers()[0]; got != 30 {
+		t.Errorf("V0: want = 30, got = %d", got)
+	}
+}
@@ -0,0 +1,109 @@
+package asm
+
+import (
+	"math"
+)
+
+// binaryOps are the binary operators of :calc expressions.
+var binaryOps = map[string]func(x, y float64) float64{
+	"+":   func(x, y float64) float64 { return x + y },
+	"-":   func(x, y float64) float64 { return x - y },
+	"*":   func(x, y float64) float64 { return x * y },
+	"/":   func(x, y float64) float64 { return x / y },
+	"%":   math.Mod,
+	"&":   func(x, y float64) float64 { return float64(int64(x) & int64(y)) },
+	"|":   func(x, y float64) float64 { return float64(int64(x) | int64(y)) },
+	"^":   func(x, y float64) float64 { return float64(int64(x) ^ int64(y)) },
+	"<<":  func(x, y float64) float64 { return float64(int64(x) << uint(y)) },
+	">>":  func(x, y float64) float64 { return float64(int64(x) >> uint(y)) },
+	"pow": math.Pow,
+	"min": math.Min,
+	"max": math.Max,
+	"<":   func(x, y float64) float64 { return truth(x < y) },
+	"<=":  func(x, y float64) float64 { return truth(x <= y) },
+	">":   func(x, y float64) float64 { return truth(x > y) },
+	">=":  func(x, y float64) float64 { return truth(x >= y) },
+	"==":  func(x, y float64) float64 { return truth(x == y) },
+	"!=":  func(x, y float64) float64 { return truth(x != y) },
+}
+
+// unaryOps are the unary operators of :calc expressions.
+var unaryOps = map[string]func(x float64) float64{
+	"-":     func(x float64) float64 { return -x },
+	"~":     func(x float64) float64 { return float64(^int64(x)) },
+	"!":     func(x float64) float64 { return truth(x == 0) },
+	"abs":   math.Abs,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"sqrt":  math.Sqrt,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+}
+
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// calc reads an expression in braces and evaluates it.
+//
+// As in Octo, binary operators have no precedence and are evaluated from right to left, so 1 - 2 + 3 is -4.
+// Operands are numbers, constants, labels which have already been defined, HERE for the current address,
+// and expressions in parentheses.
+func (a *assembler) calc() (float64, error) {
+	if err := a.expect("{"); err != nil {
+		return 0, err
+	}
+	v, err := a.expr()
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, a.errorf("expression is not a number")
+	}
+	return v, a.expect("}")
+}
+
+func (a *assembler) expr() (float64, error) {
+	x, err := a.term()
+	if err != nil {
+		return 0, err
+	}
+	op, ok := binaryOps[a.peek()]
+	if !ok {
+		return x, nil
+	}
+	a.pos++
+	y, err := a.expr()
+	if err != nil {
+		return 0, err
+	}
+	return op(x, y), nil
+}
+
+func (a *assembler) term() (float64, error) {
+	t, err := a.next()
+	if err != nil {
+		return 0, err
+	}
+	if op, ok := unaryOps[t]; ok {
+		x, err := a.term()
+		if err != nil {
+			return 0, err
+		}
+		return op(x), nil
+	}
+	switch t {
+	case "(":
+		x, err := a.expr()
+		if err != nil {
+			return 0, err
+		}
+		return x, a.expect(")")
+	case "HERE":
+		return float64(a.here), nil
+	}
+	return a.value(t)
+}
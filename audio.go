@@ -0,0 +1,145 @@
+package chip8
+
+import (
+	"math"
+)
+
+// Sound is the state of the sound output of the interpreter.
+type Sound struct {
+	// On is set while the sound timer is nonzero, when the buzzer sounds.
+	On bool
+
+	// Pattern is the XO-CHIP audio pattern buffer, 128 1-bit samples played in a loop while the sound is on,
+	// starting from the most significant bit of the first byte. Pitch sets the rate they are played at.
+	Pattern [16]uint8
+	Pitch   uint8
+}
+
+// Rate returns the number of bits of the pattern played per second, which is 4000*2^((Pitch-64)/48).
+func (s Sound) Rate() float64 {
+	return 4000 * math.Pow(2, (float64(s.Pitch)-defaultPitch)/48)
+}
+
+// Audio plays the sound of an interpreter.
+type Audio interface {
+	// Sound is called with the new state of the sound whenever it changes: when the sound timer becomes nonzero or
+	// reaches zero, and when an XO-CHIP program changes the pattern or pitch.
+	Sound(s Sound)
+}
+
+// SetAudio sets the audio output, which is immediately told the current sound and then notified of every change.
+// If a is nil, the sound is not played.
+func (ip *Interpreter) SetAudio(a Audio) {
+	ip.audio = a
+	ip.sound = ip.Sound()
+	if a != nil {
+		a.Sound(ip.sound)
+	}
+}
+
+// Sound returns the current state of the sound.
+func (ip *Interpreter) Sound() Sound {
+	return Sound{
+		On:      ip.st > 0,
+		Pattern: ip.pattern,
+		Pitch:   ip.pitch,
+	}
+}
+
+// updateSound notifies the audio output if the sound has changed.
+func (ip *Interpreter) updateSound() {
+	if ip.audio == nil {
+		return
+	}
+	if s := ip.Sound(); s != ip.sound {
+		ip.sound = s
+		ip.audio.Sound(s)
+	}
+}
+
+// Levels of the unsigned 8-bit samples generated by Synth.
+const (
+	synthSilence = 0x80
+	synthHigh    = 0xA0
+	synthLow     = 0x60
+)
+
+// buzzerPattern is the pattern played by Synth for programs which have not loaded one, a 500 Hz square wave at the
+// default pitch.
+var buzzerPattern = [16]uint8{
+	0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0,
+	0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0,
+}
+
+// Synth is an Audio which turns the sound into mono unsigned 8-bit PCM samples, for audio outputs which play or
+// save a waveform. While the sound is on, it plays the pattern at the rate set by the pitch as a square wave.
+// Programs which never load a pattern leave it empty, so an empty pattern plays a 500 Hz buzzer instead.
+type Synth struct {
+	// SampleRate is the number of samples per second.
+	SampleRate int
+
+	sound Sound
+
+	// pos is the position in the pattern in bits, which is kept between calls so that the wave is continuous.
+	pos float64
+
+	// heard is set if the sound has been on since the last frame generated by Frame.
+	heard bool
+
+	// frames is the number of frames generated by Frame, to spread sample rates which are not a multiple of 60
+	// evenly over the frames.
+	frames int
+}
+
+// NewSynth returns a synthesizer which generates sampleRate samples per second.
+func NewSynth(sampleRate int) *Synth {
+	return &Synth{SampleRate: sampleRate}
+}
+
+func (s *Synth) Sound(sound Sound) {
+	s.sound = sound
+	s.heard = s.heard || sound.On
+}
+
+// Samples appends n samples of the current sound to buf and returns the extended buffer.
+func (s *Synth) Samples(buf []byte, n int) []byte {
+	return s.samples(buf, n, s.sound.On)
+}
+
+// samples appends n samples to buf, which are silent unless on is set.
+func (s *Synth) samples(buf []byte, n int, on bool) []byte {
+	if !on {
+		s.pos = 0
+		for i := 0; i < n; i++ {
+			buf = append(buf, synthSilence)
+		}
+		return buf
+	}
+	pattern := s.sound.Pattern
+	if pattern == ([16]uint8{}) {
+		pattern = buzzerPattern
+	}
+	bits := float64(8 * len(pattern))
+	step := s.sound.Rate() / float64(s.SampleRate)
+	for i := 0; i < n; i++ {
+		bit := int(s.pos)
+		if pattern[bit/8]&(0x80>>uint(bit%8)) != 0 {
+			buf = append(buf, synthHigh)
+		} else {
+			buf = append(buf, synthLow)
+		}
+		s.pos = math.Mod(s.pos+step, bits)
+	}
+	return buf
+}
+
+// Frame appends the samples for the 60 Hz frame which has just ended to buf and returns the extended buffer.
+// The frame is audible if the sound was on at any time during it, so that a sound timer set to n sounds for n frames
+// even though it is decremented at the end of the frame, before Frame is called.
+func (s *Synth) Frame(buf []byte) []byte {
+	n := (s.frames+1)*s.SampleRate/60 - s.frames*s.SampleRate/60
+	s.frames = (s.frames + 1) % 60
+	buf = s.samples(buf, n, s.heard)
+	s.heard = s.sound.On
+	return buf
+}
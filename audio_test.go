@@ -0,0 +1,110 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// recordedAudio records every sound it is told about.
+type recordedAudio []Sound
+
+func (a *recordedAudio) Sound(s Sound) {
+	*a = append(*a, s)
+}
+
+func TestInterpreter_SetAudio(t *testing.T) {
+	ip := New(nil, nil, QuirksXOCHIP)
+	ip.SetInstructionsPerFrame(2)
+	ip.Load([]byte{
+		0x60, 0x02, // LD V0, 2
+		0xF0, 0x18, // LD ST, V0
+		0x61, 0x70, // LD V1, 0x70
+		0xF1, 0x3A, // PITCH V1
+		0x12, 0x08, // JP 0x208
+	})
+	var audio recordedAudio
+	ip.SetAudio(&audio)
+	for i := 0; i < 4; i++ {
+		if err := ip.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := recordedAudio{
+		{Pitch: defaultPitch},
+		{On: true, Pitch: defaultPitch},
+		{On: true, Pitch: 0x70},
+		{Pitch: 0x70},
+	}
+	if diff := cmp.Diff(want, audio); diff != "" {
+		t.Errorf("sounds (- want, + got):\n%s", diff)
+	}
+}
+
+func TestSynth_Samples(t *testing.T) {
+	const h, l, s = synthHigh, synthLow, synthSilence
+	tests := []struct {
+		name  string
+		sound Sound
+		n     int
+		want  []byte
+	}{
+		{
+			name:  "off",
+			sound: Sound{Pattern: [16]uint8{0xFF}, Pitch: defaultPitch},
+			n:     4,
+			want:  []byte{s, s, s, s},
+		},
+		{
+			name:  "pattern",
+			sound: Sound{On: true, Pattern: [16]uint8{0xC5}, Pitch: defaultPitch},
+			n:     10,
+			want:  []byte{h, h, l, l, l, h, l, h, l, l},
+		},
+		{
+			name:  "double rate",
+			sound: Sound{On: true, Pattern: [16]uint8{0xC5}, Pitch: defaultPitch + 48},
+			n:     4,
+			want:  []byte{h, l, l, l},
+		},
+		{
+			name:  "buzzer",
+			sound: Sound{On: true, Pitch: defaultPitch},
+			n:     10,
+			want:  []byte{h, h, h, h, l, l, l, l, h, h},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// one bit of the pattern per sample at the default pitch
+			synth := NewSynth(4000)
+			synth.Sound(tt.sound)
+			if diff := cmp.Diff(tt.want, synth.Samples(nil, tt.n)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestSynth_Frame(t *testing.T) {
+	synth := NewSynth(600)
+	synth.Sound(Sound{On: true, Pitch: defaultPitch})
+	synth.Sound(Sound{Pitch: defaultPitch})
+	if got := synth.Frame(nil); got[0] == synthSilence {
+		t.Errorf("a frame in which the sound was on is silent: %X", got)
+	}
+	if got := synth.Frame(nil); got[0] != synthSilence {
+		t.Errorf("a frame in which the sound was off is not silent: %X", got)
+	}
+}
+
+func TestSynth_Frame_sampleRate(t *testing.T) {
+	synth := NewSynth(22050)
+	var buf []byte
+	for i := 0; i < 60; i++ {
+		buf = synth.Frame(buf)
+	}
+	if len(buf) != 22050 {
+		t.Errorf("samples in 60 frames: want = 22050, got = %d", len(buf))
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// audioOutput is where the frontend plays the sound of the interpreter.
+type audioOutput interface {
+	chip8.Audio
+
+	// frame outputs the sound of the frame which has just ended.
+	frame()
+
+	// close finishes the output and returns the first error writing it, if any.
+	close() error
+}
+
+// newAudio returns the audio output chosen by opts, or nil if there is none.
+// The bell is not used in headless mode, where stdout is used for the final state.
+func newAudio(opts options) (audioOutput, error) {
+	switch opts.audio {
+	case "bell":
+		if opts.headless {
+			return nil, nil
+		}
+		return &bellAudio{w: os.Stdout}, nil
+	case "wav":
+		w, err := newWAVAudio(opts.audioPath, opts.sampleRate)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	case "pcm":
+		if opts.audioPath == "" {
+			return &pcmAudio{Synth: chip8.NewSynth(opts.sampleRate), w: os.Stdout}, nil
+		}
+		// named pipes are opened write-only, which waits for a player to open the other end
+		f, err := os.OpenFile(opts.audioPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return &pcmAudio{Synth: chip8.NewSynth(opts.sampleRate), w: f, closer: f}, nil
+	}
+	return nil, nil
+}
+
+// bellAudio rings the terminal bell each time the sound comes on. Terminals cannot hold a tone,
+// so the length of the sound and the XO-CHIP pattern are ignored.
+type bellAudio struct {
+	w  io.Writer
+	on bool
+}
+
+func (b *bellAudio) Sound(s chip8.Sound) {
+	if s.On && !b.on {
+		b.w.Write([]byte("\a"))
+	}
+	b.on = s.On
+}
+
+func (b *bellAudio) frame() {}
+
+func (b *bellAudio) close() error {
+	return nil
+}
+
+// pcmAudio writes the sound as a stream of raw mono unsigned 8-bit samples, which players can read from stdout or
+// a named pipe, for example with aplay -f U8 -r 44100, or ffplay -f u8 -ar 44100 -ac 1 -i -.
+type pcmAudio struct {
+	*chip8.Synth
+	w io.Writer
+
+	// closer closes w, or is nil if w is stdout.
+	closer io.Closer
+
+	buf []byte
+	err error
+}
+
+func (p *pcmAudio) frame() {
+	p.buf = p.Frame(p.buf[:0])
+	if p.err == nil {
+		_, p.err = p.w.Write(p.buf)
+	}
+}
+
+func (p *pcmAudio) close() error {
+	if p.closer != nil {
+		if err := p.closer.Close(); p.err == nil {
+			p.err = err
+		}
+	}
+	return p.err
+}
+
+// wavAudio writes the sound to a WAV file of mono unsigned 8-bit samples.
+// The sizes in the header are filled in when the file is closed.
+type wavAudio struct {
+	pcmAudio
+	ws io.WriteSeeker
+	n  int
+}
+
+// wavHeader is the header of a WAV file of PCM samples, which is followed by the samples.
+type wavHeader struct {
+	RIFF          [4]byte
+	RIFFSize      uint32
+	WAVE          [4]byte
+	Fmt           [4]byte
+	FmtSize       uint32
+	Format        uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+	Data          [4]byte
+	DataSize      uint32
+}
+
+// wavHeaderSize is the encoded size of wavHeader.
+const wavHeaderSize = 44
+
+// newWAVAudio creates a WAV file at path with sampleRate samples per second.
+func newWAVAudio(path string, sampleRate int) (*wavAudio, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := newWAVWriter(f, f, sampleRate)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// newWAVWriter writes a WAV file with sampleRate samples per second to ws, and closes it with closer,
+// which may be nil.
+func newWAVWriter(ws io.WriteSeeker, closer io.Closer, sampleRate int) (*wavAudio, error) {
+	w := &wavAudio{
+		pcmAudio: pcmAudio{Synth: chip8.NewSynth(sampleRate), w: ws, closer: closer},
+		ws:       ws,
+	}
+	if err := w.writeHeader(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *wavAudio) writeHeader() error {
+	header := wavHeader{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		RIFFSize:      uint32(wavHeaderSize - 8 + w.n),
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		Format:        1, // PCM
+		Channels:      1,
+		SampleRate:    uint32(w.SampleRate),
+		ByteRate:      uint32(w.SampleRate),
+		BlockAlign:    1,
+		BitsPerSample: 8,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      uint32(w.n),
+	}
+	if _, err := w.ws.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return binary.Write(w.ws, binary.LittleEndian, header)
+}
+
+func (w *wavAudio) frame() {
+	w.pcmAudio.frame()
+	w.n += len(w.buf)
+}
+
+func (w *wavAudio) close() error {
+	if w.err == nil {
+		w.err = w.writeHeader()
+	}
+	return w.pcmAudio.close()
+}
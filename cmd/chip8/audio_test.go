@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// memFile is an in-memory file which can be written and seeked.
+type memFile struct {
+	data []byte
+	pos  int
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if end := f.pos + len(p); end > len(f.data) {
+		f.data = append(f.data, make([]byte, end-len(f.data))...)
+	}
+	copy(f.data[f.pos:], p)
+	f.pos += len(p)
+	return len(p), nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += int64(f.pos)
+	case io.SeekEnd:
+		offset += int64(len(f.data))
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.pos = int(offset)
+	return offset, nil
+}
+
+func TestWAVAudio(t *testing.T) {
+	const sampleRate = 600 // 10 samples per frame
+	var f memFile
+	w, err := newWAVWriter(&f, nil, sampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Sound(chip8.Sound{On: true})
+	for i := 0; i < 3; i++ {
+		w.frame()
+	}
+	w.Sound(chip8.Sound{})
+	w.frame()
+	w.frame()
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	const samples = 5 * sampleRate / 60
+	if len(f.data) != wavHeaderSize+samples {
+		t.Fatalf("want %d bytes, got %d", wavHeaderSize+samples, len(f.data))
+	}
+	var header wavHeader
+	if err := binary.Read(bytes.NewReader(f.data), binary.LittleEndian, &header); err != nil {
+		t.Fatal(err)
+	}
+	want := wavHeader{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		RIFFSize:      wavHeaderSize - 8 + samples,
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		Format:        1,
+		Channels:      1,
+		SampleRate:    sampleRate,
+		ByteRate:      sampleRate,
+		BlockAlign:    1,
+		BitsPerSample: 8,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      samples,
+	}
+	if diff := cmp.Diff(want, header); diff != "" {
+		t.Error(diff)
+	}
+
+	data := f.data[wavHeaderSize:]
+	// the sound is heard in the frame in which it is turned off, and is silent afterwards
+	for i, b := range data {
+		if silent := i >= 4*sampleRate/60; silent != (b == 0x80) {
+			t.Errorf("sample %d: want silent = %t, got 0x%02X", i, silent, b)
+		}
+	}
+}
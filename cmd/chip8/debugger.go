@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// The debugger panes are drawn to the right of and below the game screen,
+// which needs this many extra columns and rows.
+const (
+	debuggerWidth  = 40
+	debuggerHeight = 11
+)
+
+const (
+	// disasmLines is the number of instructions shown in the disassembly pane,
+	// and disasmBefore is how many of them come before the program counter.
+	disasmLines  = 16
+	disasmBefore = 6
+
+	// memoryRows is the number of rows of 16 bytes shown in the memory pane.
+	memoryRows = 8
+)
+
+// Debugger hotkeys
+const (
+	keyContinue     = tcell.KeyF9
+	keyStepOver     = tcell.KeyF10
+	keyStep         = tcell.KeyF11
+	keyStepOut      = tcell.KeyF12
+	keyBreakpoint   = tcell.KeyCtrlB
+	keyMemoryWindow = tcell.KeyCtrlG
+)
+
+const debuggerHelp = "F9 run/break  F10 over  F11 step  F12 out  ^B breakpoint  ^G memory"
+
+// debugger draws the interpreter state beside the game screen and controls a machine from the keyboard.
+// Apart from handleKey, its methods must only be called on the machine goroutine.
+type debugger struct {
+	screen tcell.Screen
+	m      *machine
+
+	// gameSize returns the columns and rows taken up by the game screen for a display of w by h pixels,
+	// which the panes are drawn around.
+	gameSize func(w, h int) (int, int)
+
+	// memoryAddr is the first address shown in the memory pane, or -1 to follow I.
+	memoryAddr int
+
+	// While prompting for an address, prompt is the question and input is what has been typed so far.
+	prompt string
+	input  string
+	submit func(addr uint16)
+
+	// status is a message shown in the status line, such as the last fault.
+	status string
+}
+
+// newDebugger pauses m and attaches a debugger to it.
+func newDebugger(screen tcell.Screen, m *machine, gameSize func(w, h int) (int, int)) *debugger {
+	d := &debugger{
+		screen:     screen,
+		m:          m,
+		gameSize:   gameSize,
+		memoryAddr: -1,
+	}
+	m.paused = true
+	m.update = d.draw
+	return d
+}
+
+// handleKey acts on a key press and reports whether it was a debugger key.
+func (d *debugger) handleKey(ev *tcell.EventKey) bool {
+	var handled bool
+	d.m.do(func(ip *chip8.Interpreter) {
+		handled = d.key(ip, ev)
+	})
+	return handled
+}
+
+func (d *debugger) key(ip *chip8.Interpreter, ev *tcell.EventKey) bool {
+	if d.prompt != "" {
+		d.promptKey(ev)
+		return true
+	}
+	d.status = ""
+	switch ev.Key() {
+	case keyContinue:
+		if d.m.paused {
+			d.resume(ip, nil)
+		} else {
+			d.m.paused = true
+		}
+	case keyStep:
+		if d.m.paused {
+			d.step(ip)
+		}
+	case keyStepOver:
+		if !d.m.paused {
+			break
+		}
+		pc, sp := ip.PC(), ip.SP()
+		if ip.Memory()[pc]>>4 != 0x2 {
+			d.step(ip)
+			break
+		}
+		// run until the subroutine returns to the next instruction
+		d.resume(ip, func(ip *chip8.Interpreter) bool {
+			return ip.PC() == pc+2 && ip.SP() == sp
+		})
+	case keyStepOut:
+		if !d.m.paused {
+			break
+		}
+		sp := ip.SP()
+		if sp == 0 {
+			d.status = "not in a subroutine"
+			break
+		}
+		d.resume(ip, func(ip *chip8.Interpreter) bool {
+			return ip.SP() < sp
+		})
+	case keyBreakpoint:
+		d.ask("Toggle breakpoint at (default PC): ", func(addr uint16) {
+			if d.input == "" {
+				addr = ip.PC()
+			}
+			if d.m.breakpoints[addr] {
+				delete(d.m.breakpoints, addr)
+			} else {
+				d.m.breakpoints[addr] = true
+			}
+		})
+	case keyMemoryWindow:
+		d.ask("Show memory at (default I): ", func(addr uint16) {
+			if d.input == "" {
+				d.memoryAddr = -1
+			} else {
+				d.memoryAddr = int(addr)
+			}
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+func (d *debugger) step(ip *chip8.Interpreter) {
+	if err := ip.Step(); err != nil {
+		d.status = err.Error()
+	}
+}
+
+func (d *debugger) resume(ip *chip8.Interpreter, until func(ip *chip8.Interpreter) bool) {
+	if err := d.m.resume(until); err != nil {
+		d.status = err.Error()
+	}
+}
+
+// ask prompts for a hexadecimal address and calls submit with it once it has been entered.
+// If nothing is entered, submit is called with 0.
+func (d *debugger) ask(prompt string, submit func(addr uint16)) {
+	d.prompt = prompt
+	d.input = ""
+	d.submit = submit
+}
+
+func (d *debugger) promptKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		addr, err := strconv.ParseUint(d.input, 16, 16)
+		if err != nil && d.input != "" {
+			d.status = fmt.Sprintf("invalid address: %s", d.input)
+		} else {
+			d.submit(uint16(addr))
+		}
+		d.prompt = ""
+	case tcell.KeyEscape:
+		d.prompt = ""
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if d.input != "" {
+			d.input = d.input[:len(d.input)-1]
+		}
+	case tcell.KeyRune:
+		if len(d.input) < 4 && strings.ContainsRune("0123456789abcdefABCDEF", ev.Rune()) {
+			d.input += string(ev.Rune())
+		}
+	}
+}
+
+// draw draws the debugger panes for the current state of ip.
+func (d *debugger) draw(ip *chip8.Interpreter) {
+	display := ip.Display()
+	w, h := d.gameSize(display.Width(), display.Height())
+	x, y := w+1, h+1
+	d.drawRegisters(ip, x, 0)
+	d.drawStack(ip, x+30, 0)
+	d.drawDisassembly(ip, x, 7)
+	d.drawMemory(ip, 0, y)
+	d.drawStatus(ip, 0, y+memoryRows+1)
+	d.screen.Show()
+}
+
+func (d *debugger) drawRegisters(ip *chip8.Interpreter, x, y int) {
+	v := ip.Registers()
+	for row := 0; row < 4; row++ {
+		var s string
+		for col := 0; col < 4; col++ {
+			i := row*4 + col
+			s += fmt.Sprintf("V%X=%02X ", i, v[i])
+		}
+		d.text(x, y+row, 28, s)
+	}
+	d.text(x, y+4, 28, fmt.Sprintf("I=%04X PC=%04X SP=%X", ip.I(), ip.PC(), ip.SP()))
+	d.text(x, y+5, 28, fmt.Sprintf("DT=%02X ST=%02X", ip.DT(), ip.ST()))
+}
+
+func (d *debugger) drawStack(ip *chip8.Interpreter, x, y int) {
+	stack := ip.Stack()
+	d.text(x, y, 8, "Stack")
+	for i := range stack {
+		var s string
+		if i < int(ip.SP()) {
+			s = fmt.Sprintf("%X %04X", i, stack[i])
+		}
+		d.text(x, y+1+i, 8, s)
+	}
+}
+
+// drawDisassembly disassembles the instructions around the program counter. Instructions before the program
+// counter are assumed to be two bytes long, since there is no way to tell where they start.
+func (d *debugger) drawDisassembly(ip *chip8.Interpreter, x, y int) {
+	mem := ip.Memory()
+	addr := int(ip.PC()) - 2*disasmBefore
+	if addr < 0 {
+		addr = int(ip.PC()) % 2
+	}
+	for line := 0; line < disasmLines; line++ {
+		var s string
+		if addr < len(mem) {
+			asm, n := chip8.Disassemble(mem, addr)
+			mark := "  "
+			if d.m.breakpoints[uint16(addr)] {
+				mark = "* "
+			}
+			if addr == int(ip.PC()) {
+				mark = mark[:1] + ">"
+			}
+			s = fmt.Sprintf("%s%04X %X %s", mark, addr, mem[addr:addr+n], asm)
+			addr += n
+		}
+		d.text(x, y+line, 30, s)
+	}
+}
+
+func (d *debugger) drawMemory(ip *chip8.Interpreter, x, y int) {
+	mem := ip.Memory()
+	addr := d.memoryAddr
+	if addr < 0 {
+		addr = int(ip.I()) &^ 0xF
+	}
+	if last := len(mem) - memoryRows*16; addr > last {
+		addr = last
+	}
+	for row := 0; row < memoryRows; row++ {
+		s := fmt.Sprintf("%04X ", addr)
+		for i := 0; i < 16; i++ {
+			s += fmt.Sprintf(" %02X", mem[addr+i])
+		}
+		d.text(x, y+row, 56, s)
+		addr += 16
+	}
+}
+
+func (d *debugger) drawStatus(ip *chip8.Interpreter, x, y int) {
+	var s string
+	switch {
+	case d.prompt != "":
+		s = d.prompt + d.input + "_"
+	case d.status != "":
+		s = d.status
+	case ip.Exited():
+		s = "Exited"
+	case d.m.paused:
+		s = fmt.Sprintf("Paused at %04X   %s", ip.PC(), debuggerHelp)
+	default:
+		s = "Running   " + debuggerHelp
+	}
+	d.text(x, y, len(debuggerHelp)+20, s)
+}
+
+// text draws s at (x, y), padded with spaces to width columns so that it overwrites anything drawn there before.
+func (d *debugger) text(x, y, width int, s string) {
+	for i, r := range []rune(s) {
+		if i >= width {
+			return
+		}
+		d.screen.SetContent(x+i, y, r, nil, tcell.StyleDefault)
+	}
+	for i := len([]rune(s)); i < width; i++ {
+		d.screen.SetContent(x+i, y, ' ', nil, tcell.StyleDefault)
+	}
+}
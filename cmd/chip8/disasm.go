@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// disasm writes a disassembly listing of prog to w.
+// Each line shows the address, the raw bytes and the instruction, and labels are written on their own line.
+func disasm(w io.Writer, prog []byte) error {
+	bw := bufio.NewWriter(w)
+	for _, line := range chip8.DisassembleProgram(prog) {
+		if line.Label != "" {
+			fmt.Fprintf(bw, "%s:\n", line.Label)
+		}
+		fmt.Fprintf(bw, "%04X  %-23s  %s\n", line.Addr, fmt.Sprintf("% X", line.Bytes), line.Asm)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// scriptedKeypad is a keypad whose keys are set by the headless runner instead of the keyboard.
+type scriptedKeypad uint16
+
+func (k *scriptedKeypad) Keys() uint16 {
+	return uint16(*k)
+}
+
+// runHeadless runs prog for opts.frames frames without a terminal, holding keys according to opts.keys,
+// or replays the movie at opts.replayPath.
+// Afterwards it writes the display to opts.pngPath and opts.asciiPath if they are set,
+// and prints the registers and memory to stdout.
+func runHeadless(prog []byte, opts options) error {
+	var scripted scriptedKeypad
+	var keypad chip8.Keypad = &scripted
+	movie, err := startMovie(prog, &opts, keypad)
+	if err != nil {
+		return err
+	}
+	frames := opts.frames
+	if movie != nil {
+		keypad = movie.keypad
+		if movie.player != nil {
+			// a replay runs for the length of the movie
+			frames = len(movie.movie.Keys)
+		}
+	}
+
+	ip := chip8.New(keypad, nil, opts.quirks)
+	ip.SetInstructionsPerFrame(opts.ipf)
+	if err := ip.LoadAt(prog, opts.loadAddr); err != nil {
+		return err
+	}
+	audio, err := newAudio(opts)
+	if err != nil {
+		return err
+	}
+	attach(ip, opts, movie, audio)
+
+	filter := newFilter(opts)
+	frame := filter.Filter(ip.Display())
+	keys := opts.keys
+	var runErr error
+	n := 0
+	for ; n < frames && !ip.Exited(); n++ {
+		for len(keys) > 0 && keys[0].frame <= n {
+			scripted = scriptedKeypad(keys[0].state)
+			keys = keys[1:]
+		}
+		if runErr = ip.RunFrame(); runErr != nil {
+			break
+		}
+		frame = filter.Filter(ip.Display())
+	}
+
+	if movie != nil {
+		if err := movie.save(opts.recordPath); err != nil {
+			return err
+		}
+	}
+	if audio != nil {
+		if err := audio.close(); err != nil {
+			return err
+		}
+	}
+	if opts.pngPath != "" {
+		if err := writePNG(opts.pngPath, &frame, opts); err != nil {
+			return err
+		}
+	}
+	if opts.asciiPath != "" {
+		if err := ioutil.WriteFile(opts.asciiPath, []byte(frame.String()), 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Ran %d frames\n", n)
+	dumpState(os.Stdout, ip)
+	return runErr
+}
+
+// writePNG writes frame to a PNG file at path, scaled up by opts.scale and drawn in opts.palette.
+func writePNG(path string, frame *chip8.Frame, opts options) error {
+	img := frame.Image(imagePalette(opts.palette))
+	if opts.scale > 1 {
+		img = scaleImage(img, opts.scale)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// scaleImage returns img with each pixel taking up scale by scale pixels.
+func scaleImage(img *image.RGBA, scale int) *image.RGBA {
+	b := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+	for y := 0; y < b.Dy()*scale; y++ {
+		for x := 0; x < b.Dx()*scale; x++ {
+			scaled.SetRGBA(x, y, img.RGBAAt(b.Min.X+x/scale, b.Min.Y+y/scale))
+		}
+	}
+	return scaled
+}
+
+// dumpState prints the registers, stack and memory of ip to w.
+// Runs of identical lines of memory are collapsed into a single *, like hexdump.
+func dumpState(w io.Writer, ip *chip8.Interpreter) {
+	v := ip.Registers()
+	for i := range v {
+		sep := " "
+		if i%8 == 7 {
+			sep = "\n"
+		}
+		fmt.Fprintf(w, "V%X=%02X%s", i, v[i], sep)
+	}
+	fmt.Fprintf(w, "I=%04X PC=%04X SP=%X DT=%02X ST=%02X\n", ip.I(), ip.PC(), ip.SP(), ip.DT(), ip.ST())
+	fmt.Fprint(w, "Stack:")
+	stack := ip.Stack()
+	for i := 0; i < int(ip.SP()); i++ {
+		fmt.Fprintf(w, " %04X", stack[i])
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Memory:")
+	const lineLen = 16
+	mem := ip.Memory()
+	repeated := false
+	for addr := 0; addr < len(mem); addr += lineLen {
+		line := mem[addr : addr+lineLen]
+		if addr > 0 && bytes.Equal(line, mem[addr-lineLen:addr]) {
+			if !repeated {
+				fmt.Fprintln(w, "*")
+				repeated = true
+			}
+			continue
+		}
+		repeated = false
+		fmt.Fprintf(w, "%04X  % X\n", addr, line)
+	}
+}
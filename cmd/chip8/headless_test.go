@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/yi-jiayu/chip8"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestDumpState(t *testing.T) {
+	prog := []byte{
+		0x60, 0x12, // LD V0, 0x12
+		0x6F, 0xFE, // LD VF, 0xFE
+		0xA3, 0x00, // LD I, 0x300
+		0xF0, 0x15, // LD DT, V0
+		0x22, 0x0C, // CALL 0x20C
+		0x00, 0x00,
+		0x12, 0x0C, // JP 0x20C
+	}
+	ip := chip8.New(nil, nil, chip8.Quirks{})
+	if err := ip.Load(prog); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := ip.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	dumpState(&buf, ip)
+
+	golden := filepath.Join("testdata", "dump.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), buf.String()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// hostKey is a key on the terminal's keyboard: a rune if key is tcell.KeyRune, or a special key such as an arrow key.
+type hostKey struct {
+	key tcell.Key
+	r   rune
+}
+
+// eventKey returns the host key of a key event.
+func eventKey(ev *tcell.EventKey) hostKey {
+	if ev.Key() == tcell.KeyRune {
+		return hostKey{key: tcell.KeyRune, r: ev.Rune()}
+	}
+	return hostKey{key: ev.Key()}
+}
+
+// parseHostKey parses the name of a host key: a single character, space, or a special key named as in
+// tcell.KeyNames, such as Up, Enter or PgDn, ignoring case.
+func parseHostKey(name string) (hostKey, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return hostKey{key: tcell.KeyRune, r: r}, nil
+	}
+	if strings.EqualFold(name, "space") {
+		return hostKey{key: tcell.KeyRune, r: ' '}, nil
+	}
+	for k, n := range tcell.KeyNames {
+		if strings.EqualFold(name, n) {
+			return hostKey{key: k}, nil
+		}
+	}
+	return hostKey{}, fmt.Errorf("unknown key %q", name)
+}
+
+// bindings maps host keys to the bitmask of keypad keys they press.
+type bindings map[hostKey]uint16
+
+// layoutBindings returns the bindings of the runes of a chip8.Keymap.
+func layoutBindings(keymap chip8.Keymap) bindings {
+	b := make(bindings)
+	for r, keys := range keymap {
+		b[hostKey{key: tcell.KeyRune, r: r}] = keys
+	}
+	return b
+}
+
+// keymapFile is a keymap file, which maps host keys to keypad keys, and can override the keymap for particular ROMs.
+//
+// Keymap files are JSON. A keymap file which maps the arrow keys as well as WASD to the keypad keys 5, 7, 8 and 9,
+// and space to key 6 in one ROM, looks like:
+//
+//	{
+//		"keys": {"5": ["w", "up"], "7": ["a", "left"], "8": ["s", "down"], "9": ["d", "right"]},
+//		"roms": {
+//			"<SHA-1 of the ROM>": {"name": "Example", "keys": {"6": "space"}}
+//		}
+//	}
+//
+// The hotkeys of the frontend, such as Ctrl-R, Backspace and F1 to F12, cannot be bound.
+type keymapFile struct {
+	// Keys maps each hexadecimal keypad key to the names of the host keys which press it.
+	// If it is empty, the keymap chosen with --keymap is used.
+	Keys keyNames `json:"keys"`
+
+	// ROMs are the keymaps of particular ROMs, by the hexadecimal SHA-1 hash of the ROM.
+	ROMs map[string]romKeymap `json:"roms"`
+}
+
+// romKeymap is the keymap of a particular ROM. Its keys are bound on top of the main keymap,
+// replacing the bindings of the host keys it uses.
+type romKeymap struct {
+	// Name is the name of the ROM, to make the file readable.
+	Name string   `json:"name"`
+	Keys keyNames `json:"keys"`
+}
+
+// keyNames maps hexadecimal keypad keys to the names of the host keys which press them.
+type keyNames map[string]hostKeyNames
+
+// hostKeyNames is a list of host key names, which may be written as a single string in a keymap file.
+type hostKeyNames []string
+
+func (n *hostKeyNames) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*n = hostKeyNames{name}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(n))
+}
+
+// hotkeys returns the host keys which the frontend handles before the keymap, with what they do.
+// Keymap files cannot bind them, since the bindings would never be used.
+func hotkeys() map[hostKey]string {
+	h := map[hostKey]string{
+		{key: tcell.KeyCtrlC}:     "quit",
+		{key: keyRewind}:          "rewind",
+		{key: tcell.KeyBackspace}: "rewind",
+		{key: keyReload}:          "reload",
+		{key: keyContinue}:        "the debugger",
+		{key: keyStepOver}:        "the debugger",
+		{key: keyStep}:            "the debugger",
+		{key: keyStepOut}:         "the debugger",
+		{key: keyBreakpoint}:      "the debugger",
+		{key: keyMemoryWindow}:    "the debugger",
+	}
+	for i := tcell.Key(0); i < numSaveSlots; i++ {
+		h[hostKey{key: keySave + i}] = "save states"
+		h[hostKey{key: keyLoad + i}] = "save states"
+	}
+	return h
+}
+
+// bind adds the bindings of the keys in k to b, replacing any bindings of the same host keys.
+// It returns an error if k binds a hotkey.
+func (k keyNames) bind(b bindings) error {
+	reserved := hotkeys()
+	add := make(bindings)
+	for keypadKey, names := range k {
+		i, err := strconv.ParseUint(keypadKey, 16, 4)
+		if err != nil {
+			return fmt.Errorf("invalid keypad key %q: must be a hexadecimal digit", keypadKey)
+		}
+		for _, name := range names {
+			hk, err := parseHostKey(name)
+			if err != nil {
+				return err
+			}
+			if use, ok := reserved[hk]; ok {
+				return fmt.Errorf("key %q cannot be bound: it is used for %s", name, use)
+			}
+			add[hk] |= 1 << i
+		}
+	}
+	for hk, keys := range add {
+		b[hk] = keys
+	}
+	return nil
+}
+
+// defaultKeymapPath returns the path of the keymap file in the user's config directory,
+// or an empty string if there is no config directory.
+func defaultKeymapPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "chip8", "keymap.json")
+}
+
+// loadKeymapFile reads the JSON keymap file at path.
+// It returns nil if path is empty or the file does not exist.
+func loadKeymapFile(path string) (*keymapFile, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var f keymapFile
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := f.Keys.bind(make(bindings)); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for hash, rom := range f.ROMs {
+		if err := rom.Keys.bind(make(bindings)); err != nil {
+			return nil, fmt.Errorf("%s: ROM %s: %v", path, hash, err)
+		}
+	}
+	return &f, nil
+}
+
+// romHash returns the hexadecimal SHA-1 hash of prog, which identifies it in keymap files.
+func romHash(prog []byte) string {
+	sum := chip8.ROMHash(prog)
+	return hex.EncodeToString(sum[:])
+}
+
+// keymapFor returns the bindings used to play prog: the keys of the keymap file, or layout if the file does not set
+// any, with the overrides for prog on top. f may be nil.
+func (f *keymapFile) keymapFor(prog []byte, layout chip8.Keymap) (b bindings, name string) {
+	b = layoutBindings(layout)
+	if f == nil {
+		return b, ""
+	}
+	// the keys were checked when the file was loaded
+	if len(f.Keys) > 0 {
+		b = make(bindings)
+		f.Keys.bind(b)
+	}
+	hash := romHash(prog)
+	for h, rom := range f.ROMs {
+		if strings.EqualFold(h, hash) {
+			rom.Keys.bind(b)
+			name = rom.Name
+			if name == "" {
+				name = hash
+			}
+		}
+	}
+	return b, name
+}
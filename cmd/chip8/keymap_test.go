@@ -0,0 +1,190 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+func TestParseHostKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    hostKey
+		wantErr bool
+	}{
+		{name: "w", want: hostKey{key: tcell.KeyRune, r: 'w'}},
+		{name: "W", want: hostKey{key: tcell.KeyRune, r: 'W'}},
+		{name: "é", want: hostKey{key: tcell.KeyRune, r: 'é'}},
+		{name: "space", want: hostKey{key: tcell.KeyRune, r: ' '}},
+		{name: "Up", want: hostKey{key: tcell.KeyUp}},
+		{name: "pgdn", want: hostKey{key: tcell.KeyPgDn}},
+		{name: "ENTER", want: hostKey{key: tcell.KeyEnter}},
+		{name: "", wantErr: true},
+		{name: "upp", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHostKey(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error = %t, got = %v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(hostKey{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestKeymapFile_keymapFor(t *testing.T) {
+	prog := []byte{0x12, 0x00}
+	hash := romHash(prog)
+	runeKey := func(r rune) hostKey {
+		return hostKey{key: tcell.KeyRune, r: r}
+	}
+	tests := []struct {
+		name     string
+		file     *keymapFile
+		want     bindings
+		wantName string
+	}{
+		{
+			name: "no file",
+			file: nil,
+			want: layoutBindings(chip8.NewKeymap("0123456789abcdef")),
+		},
+		{
+			name: "several host keys for one keypad key",
+			file: &keymapFile{
+				Keys: keyNames{"5": {"w", "up"}, "8": {"s"}},
+			},
+			want: bindings{runeKey('w'): 1 << 5, {key: tcell.KeyUp}: 1 << 5, runeKey('s'): 1 << 8},
+		},
+		{
+			name: "ROM override by hash",
+			file: &keymapFile{
+				Keys: keyNames{"5": {"w", "up"}},
+				ROMs: map[string]romKeymap{
+					strings.ToUpper(hash):                      {Name: "Example", Keys: keyNames{"6": {"w"}, "7": {"space"}}},
+					"0000000000000000000000000000000000000000": {Keys: keyNames{"8": {"up"}}},
+				},
+			},
+			want:     bindings{runeKey('w'): 1 << 6, {key: tcell.KeyUp}: 1 << 5, runeKey(' '): 1 << 7},
+			wantName: "Example",
+		},
+		{
+			name: "ROM override on top of the layout",
+			file: &keymapFile{
+				ROMs: map[string]romKeymap{
+					hash: {Keys: keyNames{"F": {"0"}}},
+				},
+			},
+			want: func() bindings {
+				b := layoutBindings(chip8.NewKeymap("0123456789abcdef"))
+				b[runeKey('0')] = 1 << 0xF
+				return b
+			}(),
+			wantName: hash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, name := tt.file.keymapFor(prog, chip8.NewKeymap("0123456789abcdef"))
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(hostKey{})); diff != "" {
+				t.Error(diff)
+			}
+			if name != tt.wantName {
+				t.Errorf("want name = %q, got = %q", tt.wantName, name)
+			}
+		})
+	}
+}
+
+func TestLoadKeymapFile(t *testing.T) {
+	want := &keymapFile{
+		Keys: keyNames{"5": {"w", "up"}, "6": {"space"}},
+		ROMs: map[string]romKeymap{
+			"abc": {Name: "Example", Keys: keyNames{"7": {"a"}}},
+		},
+	}
+	tests := []struct {
+		name    string
+		src     string
+		want    *keymapFile
+		wantErr bool
+	}{
+		{
+			name: "JSON",
+			src:  `{"keys": {"5": ["w", "up"], "6": "space"}, "roms": {"abc": {"name": "Example", "keys": {"7": "a"}}}}`,
+			want: want,
+		},
+		{
+			name:    "unknown field",
+			src:     `{"key": {"5": "w"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid keypad key",
+			src:     `{"keys": {"G": "w"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown host key",
+			src:     `{"keys": {"5": "nope"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "hotkey",
+			src:     `{"keys": {"5": ["w", "F1"]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "ROM hotkey",
+			src:     `{"roms": {"abc": {"keys": {"5": "ctrl-r"}}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid ROM keys",
+			src:     `{"roms": {"abc": {"keys": {"10": "w"}}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed JSON",
+			src:     `{"keys": `,
+			wantErr: true,
+		},
+	}
+	dir, err := ioutil.TempDir("", "keymap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "keymap.json")
+			if err := ioutil.WriteFile(path, []byte(tt.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadKeymapFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error = %t, got = %v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		got, err := loadKeymapFile(filepath.Join(dir, "missing.json"))
+		if got != nil || err != nil {
+			t.Errorf("want nil, nil, got = %v, %v", got, err)
+		}
+	})
+}
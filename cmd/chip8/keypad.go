@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// The on-screen keypad draws each key as [k], with a column between keys and a row between rows of keys.
+const (
+	keypadWidth  = 4*keypadKeyWidth - 1
+	keypadHeight = 4*2 - 1
+
+	keypadKeyWidth = 4
+)
+
+// clickHoldTime is how long a clicked key stays down after the mouse button is released, so that a quick click
+// is held for at least a frame and the ROM sees it.
+const clickHoldTime = time.Second / 60
+
+// keypadLayout is the layout of the keys of the Chip-8 keypad.
+var keypadLayout = [4][4]uint8{
+	{0x1, 0x2, 0x3, 0xC},
+	{0x4, 0x5, 0x6, 0xD},
+	{0x7, 0x8, 0x9, 0xE},
+	{0xA, 0x0, 0xB, 0xF},
+}
+
+// onScreenKeypad is the Chip-8 keypad drawn beside the display, which highlights the keys which are held down and
+// can be clicked with the mouse. It is also a chip8.Keypad which holds the key under the mouse while the left button
+// is down, and for clickHoldTime after it is released.
+//
+// It is safe to use from multiple goroutines.
+type onScreenKeypad struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	// x and y are the position of the top left of the keypad.
+	x, y int
+
+	// clicked is the bitmask of the key held down with the mouse.
+	clicked uint16
+
+	// released is the bitmask of the key last released with the mouse, which stays down until until.
+	released uint16
+	until    time.Time
+}
+
+// newOnScreenKeypad returns an on-screen keypad at the top left of the screen.
+func newOnScreenKeypad() *onScreenKeypad {
+	return &onScreenKeypad{now: time.Now}
+}
+
+// move moves the keypad to x, y.
+func (p *onScreenKeypad) move(x, y int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.x, p.y = x, y
+}
+
+// draw draws the keypad, highlighting the keys in the bitmask keys.
+func (p *onScreenKeypad) draw(screen tcell.Screen, keys uint16) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for row := range keypadLayout {
+		for col, key := range keypadLayout[row] {
+			style := tcell.StyleDefault
+			if keys&(1<<key) != 0 {
+				style = style.Reverse(true)
+			}
+			for i, r := range fmt.Sprintf("[%X]", key) {
+				screen.SetContent(p.x+col*keypadKeyWidth+i, p.y+2*row, r, nil, style)
+			}
+		}
+	}
+}
+
+// mouse handles a mouse event at x, y, holding the key under the mouse while the left button is down.
+func (p *onScreenKeypad) mouse(x, y int, buttons tcell.ButtonMask) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var clicked uint16
+	if buttons&tcell.Button1 != 0 {
+		clicked = p.keyAt(x, y)
+	}
+	if p.clicked != 0 && clicked != p.clicked {
+		p.released, p.until = p.clicked, p.now().Add(clickHoldTime)
+	}
+	p.clicked = clicked
+}
+
+// keyAt returns the bitmask of the key at x, y, or 0 if x, y is not on a key.
+func (p *onScreenKeypad) keyAt(x, y int) uint16 {
+	col, row := x-p.x, y-p.y
+	if col < 0 || row < 0 || col >= keypadWidth || row >= keypadHeight || row%2 != 0 ||
+		col%keypadKeyWidth == keypadKeyWidth-1 {
+		return 0
+	}
+	return 1 << keypadLayout[row/2][col/keypadKeyWidth]
+}
+
+func (p *onScreenKeypad) Keys() uint16 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := p.clicked
+	if p.released != 0 && p.now().Before(p.until) {
+		keys |= p.released
+	}
+	return keys
+}
+
+// keypads is a keypad whose keys are held down when they are held down on any of its keypads.
+type keypads []chip8.Keypad
+
+func (k keypads) Keys() uint16 {
+	var keys uint16
+	for _, keypad := range k {
+		keys |= keypad.Keys()
+	}
+	return keys
+}
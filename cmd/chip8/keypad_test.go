@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestOnScreenKeypad_mouse(t *testing.T) {
+	tests := []struct {
+		name    string
+		x, y    int
+		buttons tcell.ButtonMask
+		want    uint16
+	}{
+		{name: "first key", x: 10, y: 5, buttons: tcell.Button1, want: 1 << 0x1},
+		{name: "label of first key", x: 11, y: 5, buttons: tcell.Button1, want: 1 << 0x1},
+		{name: "end of first key", x: 12, y: 5, buttons: tcell.Button1, want: 1 << 0x1},
+		{name: "gap after first key", x: 13, y: 5, buttons: tcell.Button1, want: 0},
+		{name: "second key", x: 14, y: 5, buttons: tcell.Button1, want: 1 << 0x2},
+		{name: "gap before last column", x: 21, y: 5, buttons: tcell.Button1, want: 0},
+		{name: "last column", x: 22, y: 5, buttons: tcell.Button1, want: 1 << 0xC},
+		{name: "end of last column", x: 24, y: 5, buttons: tcell.Button1, want: 1 << 0xC},
+		{name: "second row", x: 14, y: 7, buttons: tcell.Button1, want: 1 << 0x5},
+		{name: "last key", x: 24, y: 11, buttons: tcell.Button1, want: 1 << 0xF},
+		{name: "bottom row", x: 14, y: 11, buttons: tcell.Button1, want: 1 << 0x0},
+		{name: "first odd row", x: 10, y: 6, buttons: tcell.Button1, want: 0},
+		{name: "last odd row", x: 14, y: 10, buttons: tcell.Button1, want: 0},
+		{name: "left of pad", x: 9, y: 5, buttons: tcell.Button1, want: 0},
+		{name: "above pad", x: 10, y: 4, buttons: tcell.Button1, want: 0},
+		{name: "right of pad", x: 25, y: 5, buttons: tcell.Button1, want: 0},
+		{name: "below pad", x: 10, y: 12, buttons: tcell.Button1, want: 0},
+		{name: "no button", x: 10, y: 5, want: 0},
+		{name: "right button", x: 10, y: 5, buttons: tcell.Button2, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newOnScreenKeypad()
+			p.move(10, 5)
+			p.mouse(tt.x, tt.y, tt.buttons)
+			if got := p.Keys(); got != tt.want {
+				t.Errorf("want = %016b, got = %016b", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOnScreenKeypad_Keys(t *testing.T) {
+	var now time.Time
+	p := newOnScreenKeypad()
+	p.now = func() time.Time {
+		return now
+	}
+	steps := []struct {
+		name    string
+		advance time.Duration
+		x, y    int
+		buttons tcell.ButtonMask
+		want    uint16
+	}{
+		{name: "click", buttons: tcell.Button1, want: 1 << 0x1},
+		{name: "held while down", advance: time.Second, buttons: tcell.Button1, want: 1 << 0x1},
+		{name: "release", want: 1 << 0x1},
+		{name: "held after release", advance: clickHoldTime - 1, want: 1 << 0x1},
+		{name: "hold expires", advance: 1, want: 0},
+		{name: "quick click", buttons: tcell.Button1, want: 1 << 0x1},
+		{name: "drag to another key", x: 4, buttons: tcell.Button1, want: 1<<0x1 | 1<<0x2},
+		{name: "first key comes up", advance: clickHoldTime, x: 4, buttons: tcell.Button1, want: 1 << 0x2},
+		{name: "drag off the pad", x: 100, buttons: tcell.Button1, want: 1 << 0x2},
+		{name: "dragged key comes up", advance: clickHoldTime, x: 100, buttons: tcell.Button1, want: 0},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		p.mouse(step.x, step.y, step.buttons)
+		if got := p.Keys(); got != step.want {
+			t.Errorf("%s: want = %016b, got = %016b", step.name, step.want, got)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// frameInterval is the time between frames, which run at the same 60 Hz rate as the Chip-8 timers.
+const frameInterval = time.Second / 60
+
+const (
+	// rewindSeconds is how far back the machine can be rewound.
+	rewindSeconds = 10
+
+	// rewindHold is how long the machine keeps rewinding after the rewind key is pressed.
+	// Terminals only report key presses, so a held key is detected by its key repeats,
+	// and this must be longer than the delay before the first repeat.
+	rewindHold = 500 * time.Millisecond
+)
+
+// machine runs an interpreter in real time, and lets the frontend act on the interpreter in between frames.
+type machine struct {
+	ip       *chip8.Interpreter
+	rewinder *chip8.Rewinder
+
+	// While rewinding, the machine goes back one frame every frame instead of running forwards.
+	rewindUntil time.Time
+
+	// While paused, frames are not run. The machine pauses when it reaches a breakpoint,
+	// or the first instruction for which until returns true.
+	paused      bool
+	breakpoints map[uint16]bool
+	until       func(ip *chip8.Interpreter) bool
+
+	// update is called after every frame and command if it is set.
+	update func(ip *chip8.Interpreter)
+
+	// display holds the latest display, which is sent after every frame and command so that stepping and rewinding
+	// are shown too. It is closed when the machine stops.
+	display chan chip8.Display
+
+	cmds chan func(ip *chip8.Interpreter)
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newMachine(ip *chip8.Interpreter) *machine {
+	m := &machine{
+		ip:          ip,
+		rewinder:    chip8.NewRewinder(rewindSeconds * int(time.Second/frameInterval)),
+		breakpoints: make(map[uint16]bool),
+		cmds:        make(chan func(ip *chip8.Interpreter)),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+		display:     make(chan chip8.Display, 1),
+	}
+	ip.SetBreakFunc(m.shouldBreak)
+	return m
+}
+
+func (m *machine) shouldBreak(ip *chip8.Interpreter) bool {
+	return m.breakpoints[ip.PC()] || m.until != nil && m.until(ip)
+}
+
+// run executes frames until the machine is stopped or the program exits, and returns any fault.
+func (m *machine) run() error {
+	defer close(m.done)
+	defer close(m.display)
+	if err := m.rewinder.Record(m.ip); err != nil {
+		log.Printf("recording frame: %v", err)
+	}
+	m.updated()
+	ticker := time.NewTicker(frameInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if m.paused {
+				continue
+			}
+			if now.Before(m.rewindUntil) {
+				if _, err := m.rewinder.Rewind(m.ip); err != nil {
+					log.Printf("rewinding: %v", err)
+				}
+			} else if err := m.runFrame(); err != nil {
+				return err
+			}
+			if m.ip.Exited() {
+				return nil
+			}
+		case f := <-m.cmds:
+			f(m.ip)
+		case <-m.stop:
+			return nil
+		}
+		m.updated()
+	}
+}
+
+// updated calls update and replaces the display waiting to be received with the current one.
+func (m *machine) updated() {
+	if m.update != nil {
+		m.update(m.ip)
+	}
+	select {
+	case <-m.display:
+	default:
+	}
+	m.display <- m.ip.Display()
+}
+
+// runFrame runs a single frame, pausing the machine if it stops at a breakpoint.
+func (m *machine) runFrame() error {
+	err := m.ip.RunFrame()
+	if err == chip8.ErrBreak {
+		m.paused = true
+		m.until = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := m.rewinder.Record(m.ip); err != nil {
+		log.Printf("recording frame: %v", err)
+	}
+	return nil
+}
+
+// resume executes the current instruction and unpauses the machine, which then runs until it reaches a
+// breakpoint or the first instruction for which until returns true. until may be nil.
+// Executing the current instruction first means that a machine paused at a breakpoint does not stop there again.
+func (m *machine) resume(until func(ip *chip8.Interpreter) bool) error {
+	if err := m.ip.Step(); err != nil {
+		return err
+	}
+	m.until = until
+	m.paused = false
+	return nil
+}
+
+// do calls f with the interpreter in between frames, and waits for it to return.
+// If the machine has already stopped, f is not called.
+func (m *machine) do(f func(ip *chip8.Interpreter)) {
+	called := make(chan struct{})
+	select {
+	case m.cmds <- func(ip *chip8.Interpreter) {
+		f(ip)
+		close(called)
+	}:
+		<-called
+	case <-m.done:
+	}
+}
+
+// rewind starts rewinding the machine, or keeps it rewinding if it already is.
+func (m *machine) rewind() {
+	m.do(func(ip *chip8.Interpreter) {
+		m.rewindUntil = time.Now().Add(rewindHold)
+	})
+}
+
+// reset restarts the machine with a new program loaded at addr.
+// If the program cannot be loaded, the machine is left as it was.
+func (m *machine) reset(prog []byte, addr uint16) error {
+	var err error
+	m.do(func(ip *chip8.Interpreter) {
+		s := ip.Snapshot()
+		ip.Reset()
+		if err = ip.LoadAt(prog, addr); err != nil {
+			ip.Restore(s)
+			return
+		}
+		m.rewinder.Reset()
+		m.rewindUntil = time.Time{}
+	})
+	return err
+}
+
+// quit stops the machine if it is still running.
+func (m *machine) quit() {
+	select {
+	case m.stop <- struct{}{}:
+	case <-m.done:
+	}
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/yi-jiayu/chip8"
+	"github.com/yi-jiayu/chip8/asm"
+)
+
+// Hold Backspace to rewind.
+const keyRewind = tcell.KeyBackspace2
+
+// Press Ctrl-R to reload the ROM from its file and restart it.
+const keyReload = tcell.KeyCtrlR
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage:
+  chip8 [run] [flags] [rom]   run a ROM, read from stdin if no path is given
+  chip8 asm [source]          assemble Octo source and write the ROM to stdout
+  chip8 disasm [rom]          disassemble a ROM
+
+Terminals do not report when a key is released, so a key stays down for %v after it
+is first pressed, long enough for the terminal to start repeating it, and for %v after
+each repeat. Tapping a key holds it for %v. Keys clicked on the on-screen keypad (-keypad) are
+held while the mouse button is down.
+
+Flags:
+`, chip8.DefaultRepeatDelay, chip8.DefaultHoldTime, chip8.DefaultRepeatDelay)
+	flag.PrintDefaults()
+}
+
+func main() {
+	f := defineFlags(flag.CommandLine)
+	flag.Usage = usage
+
+	// the subcommand comes before the flags
+	args := os.Args[1:]
+	command := "run"
+	if len(args) > 0 {
+		switch args[0] {
+		case "run", "asm", "disasm":
+			command, args = args[0], args[1:]
+		}
+	}
+	flag.CommandLine.Parse(args)
+
+	switch command {
+	case "disasm", "asm":
+		do := disasmCommand
+		if command == "asm" {
+			do = asmCommand
+		}
+		if err := do(flag.Arg(0)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := loadConfig(flag.CommandLine, f.config); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	opts, err := f.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if f.logPath == "" {
+		log.SetOutput(ioutil.Discard)
+	} else {
+		lf, err := os.Create(f.logPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		log.SetOutput(lf)
+	}
+
+	opts.romPath = flag.Arg(0)
+	prog, err := readInput(opts.romPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	run := run
+	if opts.headless {
+		run = runHeadless
+	}
+	if err := run(prog, opts); err != nil {
+		log.Print(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// readInput reads the file at path, or stdin if path is empty.
+func readInput(path string) ([]byte, error) {
+	if path == "" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(path)
+}
+
+// disasmCommand disassembles the ROM at path, or the ROM on stdin if path is empty, to stdout.
+func disasmCommand(path string) error {
+	prog, err := readInput(path)
+	if err != nil {
+		return err
+	}
+	return disasm(os.Stdout, prog)
+}
+
+// asmCommand assembles the Octo source at path, or the source on stdin if path is empty, and writes the ROM to stdout.
+func asmCommand(path string) error {
+	src, err := readInput(path)
+	if err != nil {
+		return err
+	}
+	prog, err := asm.Assemble(string(src))
+	if err != nil {
+		if path != "" {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		return err
+	}
+	_, err = os.Stdout.Write(prog)
+	return err
+}
+
+// reload reads the ROM at path again and restarts the machine with it.
+// ROMs read from stdin cannot be reloaded.
+func reload(m *machine, path string, addr uint16) {
+	if path == "" {
+		log.Print("cannot reload a ROM read from stdin")
+		return
+	}
+	prog, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Printf("reloading ROM: %v", err)
+		return
+	}
+	if err := m.reset(prog, addr); err != nil {
+		log.Printf("reloading ROM: %v", err)
+	}
+}
+
+// run runs prog in the terminal until the user quits or the interpreter stops.
+func run(prog []byte, opts options) error {
+	keymap, romName := opts.keymapFile.keymapFor(prog, opts.keymap)
+	if romName != "" {
+		log.Printf("using the keymap for %s", romName)
+	}
+	keyboard := chip8.NewKeyboard(nil)
+	var pad *onScreenKeypad
+	var input chip8.Keypad = keyboard
+	if opts.keypad {
+		pad = newOnScreenKeypad()
+		input = keypads{keyboard, pad}
+	}
+
+	// keypad is the keypad the interpreter reads, and shown is the keypad highlighted on the on-screen keypad
+	keypad, shown := input, input
+	movie, err := startMovie(prog, &opts, input)
+	if err != nil {
+		return err
+	}
+	if movie != nil {
+		keypad = movie.keypad
+		if movie.player != nil {
+			shown = movie.player
+		}
+	}
+
+	// the machine sends the display after every frame and command, so the interpreter does not need to
+	ip := chip8.New(keypad, nil, opts.quirks)
+	ip.SetInstructionsPerFrame(opts.ipf)
+	if err := ip.LoadAt(prog, opts.loadAddr); err != nil {
+		return err
+	}
+	audio, err := newAudio(opts)
+	if err != nil {
+		return err
+	}
+	attach(ip, opts, movie, audio)
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+
+	err = screen.Init()
+	if err != nil {
+		return err
+	}
+	defer screen.Fini()
+	if pad != nil {
+		screen.EnableMouse()
+	}
+
+	// tcell shows RGB colours as the closest colour of the terminal if it does not support true colour
+	palette := opts.palette
+
+	// layout chooses the renderer for a display of w by h pixels, leaving room for the keypad and the debugger.
+	// It depends only on the sizes of the terminal and the display, so the display loop and the debugger agree.
+	layout := func(w, h int) renderer {
+		cols, rows := screen.Size()
+		if pad != nil {
+			cols -= keypadWidth + 1
+		}
+		if opts.debug {
+			cols, rows = cols-debuggerWidth, rows-debuggerHeight
+		}
+		return chooseRenderer(opts.renderer, opts.scale, cols, rows, w, h)
+	}
+
+	// gameSize returns the columns and rows taken up by a display of w by h pixels and the keypad beside it.
+	gameSize := func(w, h int) (int, int) {
+		cols, rows := layout(w, h).size(w, h)
+		if pad != nil {
+			cols += keypadWidth + 1
+			if rows < keypadHeight {
+				rows = keypadHeight
+			}
+		}
+		return cols, rows
+	}
+
+	m := newMachine(ip)
+	var dbg *debugger
+	if opts.debug {
+		dbg = newDebugger(screen, m, gameSize)
+	}
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- m.run()
+	}()
+
+	screen.Show()
+
+	// start display loop, which filters the latest display every frame and draws it if it or the keys held down have
+	// changed, or if the terminal has been resized
+	redraw := make(chan struct{}, 1)
+	go func() {
+		filter := newFilter(opts)
+		ticker := time.NewTicker(time.Second / 60)
+		defer ticker.Stop()
+		var last chip8.Display
+		var frame chip8.Frame
+		var r renderer
+		var hires bool
+		var keys uint16
+		dirty := true
+		for {
+			select {
+			case d, ok := <-m.display:
+				if !ok {
+					// the interpreter has stopped
+					screen.PostEvent(tcell.NewEventInterrupt(nil))
+					return
+				}
+				last = d
+				continue
+			case <-redraw:
+				dirty = true
+				continue
+			case <-ticker.C:
+			}
+			next := filter.Filter(last)
+			nextKeys := shown.Keys()
+			if next == frame && (pad == nil || nextKeys == keys) && !dirty {
+				continue
+			}
+			frame, keys, dirty = next, nextKeys, false
+			if next := layout(frame.Width(), frame.Height()); next != r || frame.Hires != hires {
+				r, hires = next, frame.Hires
+				screen.Clear()
+			}
+			r.draw(screen, &frame, palette)
+			if pad != nil {
+				cols, _ := r.size(frame.Width(), frame.Height())
+				pad.move(cols+1, 0)
+				pad.draw(screen, keys)
+			}
+			screen.Show()
+		}
+	}()
+
+loop:
+	for {
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			if ev.Key() == tcell.KeyCtrlC {
+				m.quit()
+				break loop
+			}
+			if dbg != nil && dbg.handleKey(ev) {
+				continue
+			}
+			// a movie only replays exactly if the run is not changed by anything but the keys
+			if movie == nil {
+				if handleSaveStateKey(m, ev.Key()) {
+					continue
+				}
+				if ev.Key() == keyRewind || ev.Key() == tcell.KeyBackspace {
+					m.rewind()
+					continue
+				}
+				if ev.Key() == keyReload {
+					reload(m, opts.romPath, opts.loadAddr)
+					continue
+				}
+			}
+			if mask, ok := keymap[eventKey(ev)]; ok {
+				keyboard.PressKeys(mask)
+			}
+		case *tcell.EventMouse:
+			if pad != nil {
+				x, y := ev.Position()
+				pad.mouse(x, y, ev.Buttons())
+			}
+		case *tcell.EventResize:
+			screen.Sync()
+			select {
+			case redraw <- struct{}{}:
+			default:
+			}
+		case *tcell.EventInterrupt:
+			break loop
+		}
+	}
+	err = <-runErr
+	if movie != nil {
+		if saveErr := movie.save(opts.recordPath); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	if audio != nil {
+		if closeErr := audio.close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// attach seeds the random number generator of ip from movie or opts.seed, or from the time if neither is set,
+// and sets ip up to play its sound on audio, and to record or replay movie at the end of each frame.
+// movie and audio may be nil. Seeding before the program starts means that every save state and rewind snapshot
+// includes the state of the generator.
+func attach(ip *chip8.Interpreter, opts options, movie *movieRun, audio audioOutput) {
+	switch {
+	case movie != nil:
+		ip.SetSeed(movie.movie.Seed)
+	case opts.seed != 0:
+		ip.SetSeed(opts.seed)
+	default:
+		ip.SetSeed(time.Now().UnixNano())
+	}
+	if audio != nil {
+		ip.SetAudio(audio)
+	}
+	ip.SetFrameFunc(func(ip *chip8.Interpreter) {
+		if movie != nil {
+			movie.frame(ip)
+		}
+		if audio != nil {
+			audio.frame()
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// movieRun records or replays a movie of a run of a program.
+type movieRun struct {
+	movie *chip8.Movie
+
+	// keypad is the keypad the interpreter reads, which records or plays back the keys.
+	keypad chip8.Keypad
+
+	// player plays back the movie, or is nil when recording.
+	player *chip8.MoviePlayer
+
+	// frame is called at the end of each frame.
+	frame func(ip *chip8.Interpreter)
+}
+
+// startMovie starts recording a movie of prog with the keys held down on input if opts.recordPath is set,
+// or replaying the movie at opts.replayPath if it is set, in which case the ROM is run with the settings of the movie
+// instead of opts. A recording uses opts.seed, or a seed from the time if it is zero. It returns nil if neither is set.
+func startMovie(prog []byte, opts *options, input chip8.Keypad) (*movieRun, error) {
+	switch {
+	case opts.replayPath != "":
+		data, err := ioutil.ReadFile(opts.replayPath)
+		if err != nil {
+			return nil, err
+		}
+		movie := new(chip8.Movie)
+		if err := movie.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", opts.replayPath, err)
+		}
+		if err := movie.Check(prog); err != nil {
+			return nil, fmt.Errorf("%s: %w", opts.replayPath, err)
+		}
+		opts.quirks, opts.ipf, opts.loadAddr = movie.Quirks, movie.InstructionsPerFrame, movie.LoadAddress
+		player := chip8.NewMoviePlayer(movie)
+		return &movieRun{movie: movie, keypad: player, player: player, frame: player.Frame}, nil
+	case opts.recordPath != "":
+		seed := opts.seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		movie := &chip8.Movie{
+			ROMHash:              chip8.ROMHash(prog),
+			LoadAddress:          opts.loadAddr,
+			Quirks:               opts.quirks,
+			InstructionsPerFrame: opts.ipf,
+			Seed:                 seed,
+		}
+		recorder := chip8.NewMovieRecorder(movie, input)
+		return &movieRun{movie: movie, keypad: recorder, frame: recorder.Frame}, nil
+	}
+	return nil, nil
+}
+
+// save writes a recorded movie to path. A replayed movie is not written.
+func (r *movieRun) save(path string) error {
+	if r.player != nil {
+		return nil
+	}
+	data, err := r.movie.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
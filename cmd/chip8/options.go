@@ -0,0 +1,389 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// options are the settings of the terminal frontend, which are set with command line flags.
+type options struct {
+	// romPath is the path the ROM was loaded from, or empty if it was read from stdin.
+	romPath string
+
+	// loadAddr is the address the ROM is loaded at.
+	loadAddr uint16
+
+	debug  bool
+	keymap chip8.Keymap
+
+	// keymapFile is the keymap file, or nil if there is none.
+	keymapFile *keymapFile
+
+	quirks  chip8.Quirks
+	ipf     int
+	scale   int
+	palette [4]tcell.Color
+
+	// filter is the name of the display filter, and fade is the number of frames the fade filter keeps pixels lit.
+	filter string
+	fade   int
+
+	// keypad shows the keypad beside the display, which can be clicked with the mouse.
+	keypad bool
+
+	// renderer is the name of the renderer used to draw the display, or auto to choose one by the terminal size.
+	renderer string
+
+	// audio is the name of the audio output, which writes to audioPath at sampleRate samples per second if it
+	// writes samples.
+	audio      string
+	audioPath  string
+	sampleRate int
+
+	// seed seeds the random numbers generated by RND, or is zero to seed them from the time.
+	seed int64
+
+	// recordPath is the file to record a movie of the run to, and replayPath is the movie to replay.
+	recordPath string
+	replayPath string
+
+	// In headless mode, the ROM runs for a number of frames without a terminal, pressing keys from a script,
+	// and the final display is written to image and text files.
+	headless  bool
+	frames    int
+	keys      []keyEvent
+	pngPath   string
+	asciiPath string
+}
+
+// keyEvent sets the state of the keypad from a frame onwards in headless mode.
+type keyEvent struct {
+	frame int
+	state uint16
+}
+
+// quirksPresets are the compatibility presets which can be chosen with --quirks.
+var quirksPresets = map[string]chip8.Quirks{
+	"vip":    chip8.QuirksCOSMACVIP,
+	"chip48": chip8.QuirksCHIP48,
+	"schip":  chip8.QuirksSUPERCHIP,
+	"xochip": chip8.QuirksXOCHIP,
+}
+
+// keymapLayouts are the named keyboard layouts which can be chosen with --keymap.
+var keymapLayouts = map[string]string{
+	"qwerty": chip8.QwertyLayout,
+	"dvorak": chip8.DvorakLayout,
+}
+
+// flags holds the values of the command line flags before they are validated.
+type flags struct {
+	debug      bool
+	keymap     string
+	keymapFile string
+	ips        int
+	quirks     string
+	logPath    string
+
+	// displayWait overrides the DisplayWait quirk of the preset if it is set.
+	displayWait optionalBool
+
+	loadAddr uint
+	scale    int
+	palette  string
+	renderer string
+	config   string
+	filter   string
+	fade     int
+	keypad   bool
+	seed     int64
+	audio    string
+	audioOut string
+	rate     int
+	record   string
+	replay   string
+
+	headless  bool
+	frames    int
+	keys      string
+	pngPath   string
+	asciiPath string
+}
+
+// defineFlags defines the command line flags of the frontend on fs.
+func defineFlags(fs *flag.FlagSet) *flags {
+	f := new(flags)
+	fs.BoolVar(&f.debug, "debug", false, "start paused with the debugger open")
+	fs.StringVar(&f.keymap, "keymap", "dvorak",
+		"keyboard layout: qwerty, dvorak, or the 16 keys for keypad keys 0 to F in order")
+	fs.StringVar(&f.keymapFile, "keymap-file", defaultKeymapPath(),
+		"JSON file mapping keys such as Up or space to keypad keys, with overrides for ROMs by SHA-1 hash")
+	fs.IntVar(&f.ips, "ips", 60*chip8.DefaultInstructionsPerFrame, "instructions executed per second")
+	fs.StringVar(&f.quirks, "quirks", "xochip", "compatibility preset: vip, chip48, schip or xochip")
+	fs.Var(&f.displayWait, "display-wait",
+		"make each draw wait for the next 60 Hz frame like the COSMAC VIP, overriding the quirks preset")
+	fs.StringVar(&f.logPath, "log", "chip8.log", "log file, or empty to disable logging")
+	fs.UintVar(&f.loadAddr, "load-address", 0x200, "address to load the ROM at, such as 0x600 for ETI-660 programs")
+	fs.IntVar(&f.scale, "scale", 1, "width in terminal cells of each Chip-8 pixel with the block and halfblock renderers")
+	fs.StringVar(&f.palette, "palette", "default",
+		"theme (default, green, amber, lcd or octo), or comma separated colours as names or #rrggbb: "+
+			"the background and foreground, or the background, plane 1, plane 2 and both planes")
+	fs.StringVar(&f.config, "config", defaultConfigPath(),
+		"file of flag=value lines setting flags which are not given on the command line")
+	fs.StringVar(&f.renderer, "renderer", "auto",
+		"how to draw the display: block, halfblock with two pixels per cell, braille with 2x4 pixels per cell, "+
+			"or auto to use halfblock if it fits in the terminal and braille otherwise")
+	fs.StringVar(&f.filter, "filter", "none",
+		"display filter to reduce flicker: none, fade to keep pixels lit as they fade out like a CRT, "+
+			"or merge to show the pixels set in either of the last two frames")
+	fs.IntVar(&f.fade, "fade", 4, "number of frames pixels take to fade out with the fade filter")
+	fs.BoolVar(&f.keypad, "keypad", false,
+		"show the keypad beside the display, highlighting the keys held down, and press keys by clicking them")
+	fs.StringVar(&f.audio, "audio", "bell",
+		"audio output: none, bell to ring the terminal bell when the sound starts, wav to write a WAV file, "+
+			"or pcm to stream raw unsigned 8-bit mono samples to stdout or a named pipe")
+	fs.StringVar(&f.audioOut, "audio-out", "", "file to write wav audio to, or named pipe to write pcm audio to")
+	fs.IntVar(&f.rate, "sample-rate", 44100, "samples per second of wav and pcm audio")
+	fs.Int64Var(&f.seed, "seed", 0, "seed for the random numbers generated by RND, or 0 to seed them from the time")
+	fs.StringVar(&f.record, "record", "",
+		"file to record a movie of the keys pressed in each frame to, which replays the run exactly with --replay")
+	fs.StringVar(&f.replay, "replay", "",
+		"movie file to replay, running the ROM with the settings it was recorded with")
+	fs.BoolVar(&f.headless, "headless", false, "run without a terminal for a number of frames and print the final state")
+	fs.IntVar(&f.frames, "frames", 600, "number of frames to run for in headless mode")
+	fs.StringVar(&f.keys, "keys", "",
+		"keys to press in headless mode, as comma separated frame:keys pairs which hold the hexadecimal keys "+
+			"from that frame onwards, such as 10:5,20:,30:AF")
+	fs.StringVar(&f.pngPath, "png", "", "file to write the final display to as a PNG in headless mode")
+	fs.StringVar(&f.asciiPath, "ascii", "", "file to write the final display to as text in headless mode")
+	return f
+}
+
+// optionalBool is a boolean flag which records whether it was given.
+type optionalBool struct {
+	set   bool
+	value bool
+}
+
+func (b *optionalBool) String() string {
+	if b == nil || !b.set {
+		return ""
+	}
+	return strconv.FormatBool(b.value)
+}
+
+func (b *optionalBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	b.set, b.value = true, v
+	return nil
+}
+
+func (b *optionalBool) IsBoolFlag() bool {
+	return true
+}
+
+// options validates the flags and converts them into options.
+func (f *flags) options() (options, error) {
+	opts := options{
+		debug:  f.debug,
+		scale:  f.scale,
+		keypad: f.keypad,
+	}
+
+	layout, ok := keymapLayouts[f.keymap]
+	if !ok {
+		layout = f.keymap
+	}
+	if utf8.RuneCountInString(layout) != 16 {
+		return opts, fmt.Errorf("invalid keymap %q: must be qwerty, dvorak or 16 keys", f.keymap)
+	}
+	opts.keymap = chip8.NewKeymap(layout)
+	var err error
+	if opts.keymapFile, err = loadKeymapFile(f.keymapFile); err != nil {
+		return opts, err
+	}
+
+	if opts.quirks, ok = quirksPresets[f.quirks]; !ok {
+		return opts, fmt.Errorf("unknown quirks preset %q", f.quirks)
+	}
+	if f.displayWait.set {
+		opts.quirks.DisplayWait = f.displayWait.value
+	}
+
+	if f.ips < 60 {
+		return opts, fmt.Errorf("invalid instructions per second %d: must be at least 60", f.ips)
+	}
+	opts.ipf = f.ips / 60
+
+	if f.loadAddr > 0xFFFF {
+		return opts, fmt.Errorf("invalid load address 0x%X", f.loadAddr)
+	}
+	opts.loadAddr = uint16(f.loadAddr)
+
+	if f.scale < 1 {
+		return opts, fmt.Errorf("invalid scale %d: must be at least 1", f.scale)
+	}
+
+	if _, ok := renderers[f.renderer]; !ok && f.renderer != "auto" {
+		return opts, fmt.Errorf("unknown renderer %q", f.renderer)
+	}
+	opts.renderer = f.renderer
+
+	switch f.filter {
+	case "none", "fade", "merge":
+	default:
+		return opts, fmt.Errorf("unknown filter %q", f.filter)
+	}
+	if f.fade < 0 {
+		return opts, fmt.Errorf("invalid fade %d: must not be negative", f.fade)
+	}
+	opts.filter, opts.fade = f.filter, f.fade
+
+	if opts.palette, err = parsePalette(f.palette); err != nil {
+		return opts, err
+	}
+
+	if f.record != "" && f.replay != "" {
+		return opts, fmt.Errorf("cannot record and replay a movie at the same time")
+	}
+	if (f.record != "" || f.replay != "") && f.debug {
+		return opts, fmt.Errorf("cannot record or replay a movie in the debugger")
+	}
+	opts.recordPath, opts.replayPath = f.record, f.replay
+
+	switch f.audio {
+	case "none", "bell", "wav", "pcm":
+	default:
+		return opts, fmt.Errorf("unknown audio output %q", f.audio)
+	}
+	if f.audio == "wav" && f.audioOut == "" {
+		return opts, fmt.Errorf("the wav audio output needs a file set with --audio-out")
+	}
+	if f.audio == "pcm" && f.audioOut == "" && f.headless {
+		return opts, fmt.Errorf("cannot stream pcm audio to stdout in headless mode, which prints the final state there")
+	}
+	if f.rate < 60 {
+		return opts, fmt.Errorf("invalid sample rate %d: must be at least 60", f.rate)
+	}
+	opts.audio, opts.audioPath, opts.sampleRate = f.audio, f.audioOut, f.rate
+	opts.seed = f.seed
+
+	opts.headless = f.headless
+	if f.frames < 0 {
+		return opts, fmt.Errorf("invalid number of frames %d", f.frames)
+	}
+	opts.frames = f.frames
+	if opts.keys, err = parseKeys(f.keys); err != nil {
+		return opts, err
+	}
+	opts.pngPath = f.pngPath
+	opts.asciiPath = f.asciiPath
+	return opts, nil
+}
+
+// parseKeys parses a key script such as 10:5,20:,30:AF, which holds key 5 from frame 10, releases it at frame 20
+// and holds A and F from frame 30.
+func parseKeys(script string) ([]keyEvent, error) {
+	if script == "" {
+		return nil, nil
+	}
+	var events []keyEvent
+	for _, entry := range strings.Split(script, ",") {
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid key script entry %q: must be frame:keys", entry)
+		}
+		frame, err := strconv.Atoi(parts[0])
+		if err != nil || frame < 0 {
+			return nil, fmt.Errorf("invalid frame in key script entry %q", entry)
+		}
+		if len(events) > 0 && frame <= events[len(events)-1].frame {
+			return nil, fmt.Errorf("key script entry %q is not after the previous entry", entry)
+		}
+		e := keyEvent{frame: frame}
+		for _, r := range parts[1] {
+			key, err := strconv.ParseUint(string(r), 16, 4)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key %q in key script entry %q", r, entry)
+			}
+			e.state |= 1 << key
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// defaultConfigPath returns the path of the config file in the user's config directory,
+// or an empty string if there is no config directory.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "chip8", "config")
+}
+
+// loadConfig sets the flags in fs which were not given on the command line from the config file at path.
+// Each line of the file is a flag name and value separated by =, and blank lines and lines starting with # are
+// ignored. It is not an error for the file not to exist.
+func loadConfig(fs *flag.FlagSet, path string) error {
+	if path == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%s:%d: must be flag=value", path, i+1)
+		}
+		name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("%s:%d: unknown flag %q", path, i+1, name)
+		}
+		if set[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, i+1, err)
+		}
+	}
+	return nil
+}
+
+// newFilter returns the display filter chosen by opts.
+func newFilter(opts options) chip8.Filter {
+	switch opts.filter {
+	case "fade":
+		return chip8.NewPersistence(opts.fade)
+	case "merge":
+		return new(chip8.Merge)
+	}
+	return chip8.NoFilter{}
+}
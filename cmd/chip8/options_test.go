@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    []keyEvent
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			script: "",
+			want:   nil,
+		},
+		{
+			name:   "single key",
+			script: "10:5",
+			want:   []keyEvent{{frame: 10, state: 1 << 5}},
+		},
+		{
+			name:   "several keys",
+			script: "0:1a,30:,45:F0",
+			want: []keyEvent{
+				{frame: 0, state: 1<<1 | 1<<0xA},
+				{frame: 30, state: 0},
+				{frame: 45, state: 1<<0xF | 1<<0},
+			},
+		},
+		{
+			name:   "repeated key",
+			script: "3:55",
+			want:   []keyEvent{{frame: 3, state: 1 << 5}},
+		},
+		{
+			name:    "repeated frame",
+			script:  "10:1,10:2",
+			wantErr: true,
+		},
+		{
+			name:    "out of order",
+			script:  "20:1,10:2",
+			wantErr: true,
+		},
+		{
+			name:    "missing colon",
+			script:  "10",
+			wantErr: true,
+		},
+		{
+			name:    "negative frame",
+			script:  "-1:1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid frame",
+			script:  "x:1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			script:  "10:G",
+			wantErr: true,
+		},
+		{
+			name:    "trailing comma",
+			script:  "10:1,",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseKeys(tt.script)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error = %t, got = %v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(keyEvent{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
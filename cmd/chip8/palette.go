@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// themes are the named palettes which can be chosen with --palette.
+var themes = map[string]string{
+	// default draws the background and the first plane in the terminal's default colours.
+	"default": "default,default,orangered,gold",
+	"green":   "#0A140A,#33FF66,#1A8033,#B3FFC6",
+	"amber":   "#140C00,#FFB000,#805800,#FFDD99",
+	"lcd":     "#B8BCA8,#2E3326,#707563,#4B5042",
+	"octo":    "#996600,#FFCC00,#FF6600,#662200",
+}
+
+// parsePalette parses a theme name, or comma separated colours used to draw each of the four XO-CHIP colours.
+// Colour 0 is the background, colour 1 is the first plane, colour 2 is the second plane
+// and colour 3 is where both planes overlap. If only two colours are given, the background and a foreground,
+// all the planes are drawn in the foreground colour.
+func parsePalette(palette string) ([4]tcell.Color, error) {
+	var colors [4]tcell.Color
+	if theme, ok := themes[palette]; ok {
+		palette = theme
+	}
+	names := strings.Split(palette, ",")
+	if len(names) == 2 {
+		names = []string{names[0], names[1], names[1], names[1]}
+	}
+	if len(names) != len(colors) {
+		return colors, fmt.Errorf("invalid palette %q: must be a theme, or 2 or %d colours", palette, len(colors))
+	}
+	for i, name := range names {
+		c, err := parseColor(strings.TrimSpace(name))
+		if err != nil {
+			return colors, err
+		}
+		colors[i] = c
+	}
+	return colors, nil
+}
+
+// imagePalette returns the colours of palette for drawing images.
+// The terminal's default colours are replaced with the colours of chip8.DefaultPalette.
+func imagePalette(palette [4]tcell.Color) color.Palette {
+	p := make(color.Palette, len(palette))
+	for i, c := range palette {
+		if c == tcell.ColorDefault {
+			p[i] = chip8.DefaultPalette[i]
+			continue
+		}
+		r, g, b := c.RGB()
+		p[i] = color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 0xFF}
+	}
+	return p
+}
+
+// parseColor parses a colour name known to tcell, a #rrggbb hex colour or default.
+func parseColor(name string) (tcell.Color, error) {
+	name = strings.ToLower(name)
+	if name == "default" {
+		return tcell.ColorDefault, nil
+	}
+	if c := tcell.GetColor(name); c != tcell.ColorDefault {
+		return c, nil
+	}
+	return tcell.ColorDefault, fmt.Errorf("invalid colour %q", name)
+}
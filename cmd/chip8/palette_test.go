@@ -0,0 +1,111 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+func TestParsePalette(t *testing.T) {
+	tests := []struct {
+		name    string
+		palette string
+		want    [4]tcell.Color
+		wantErr bool
+	}{
+		{
+			name:    "default theme",
+			palette: "default",
+			want:    [4]tcell.Color{tcell.ColorDefault, tcell.ColorDefault, tcell.ColorOrangeRed, tcell.ColorGold},
+		},
+		{
+			name:    "green theme",
+			palette: "green",
+			want: [4]tcell.Color{
+				tcell.NewHexColor(0x0A140A), tcell.NewHexColor(0x33FF66),
+				tcell.NewHexColor(0x1A8033), tcell.NewHexColor(0xB3FFC6),
+			},
+		},
+		{
+			name:    "four colours",
+			palette: "black, #FFFFFF,Red,default",
+			want:    [4]tcell.Color{tcell.ColorBlack, tcell.NewHexColor(0xFFFFFF), tcell.ColorRed, tcell.ColorDefault},
+		},
+		{
+			name:    "two colours",
+			palette: "navy,#00FF00",
+			want: [4]tcell.Color{
+				tcell.ColorNavy, tcell.NewHexColor(0x00FF00), tcell.NewHexColor(0x00FF00), tcell.NewHexColor(0x00FF00),
+			},
+		},
+		{
+			name:    "unknown theme",
+			palette: "sepia",
+			wantErr: true,
+		},
+		{
+			name:    "three colours",
+			palette: "black,white,red",
+			wantErr: true,
+		},
+		{
+			name:    "five colours",
+			palette: "black,white,red,blue,green",
+			wantErr: true,
+		},
+		{
+			name:    "invalid colour",
+			palette: "black,notacolour",
+			wantErr: true,
+		},
+		{
+			name:    "invalid hex colour",
+			palette: "black,#GGGGGG",
+			wantErr: true,
+		},
+		{
+			name:    "empty colour",
+			palette: "black,,red,blue",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePalette(tt.palette)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error = %t, got = %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestParsePalette_themes(t *testing.T) {
+	for name := range themes {
+		if _, err := parsePalette(name); err != nil {
+			t.Errorf("theme %s: %v", name, err)
+		}
+	}
+}
+
+func TestImagePalette(t *testing.T) {
+	got := imagePalette([4]tcell.Color{tcell.ColorDefault, tcell.NewHexColor(0x123456), tcell.ColorDefault, tcell.ColorRed})
+	want := color.Palette{
+		chip8.DefaultPalette[0],
+		color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xFF},
+		chip8.DefaultPalette[2],
+		color.RGBA{R: 0xFF, A: 0xFF},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
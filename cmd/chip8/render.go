@@ -0,0 +1,163 @@
+package main
+
+import (
+	"github.com/gdamore/tcell"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// A renderer draws the display in the terminal using some number of cells per pixel.
+type renderer interface {
+	// size returns the number of columns and rows of cells taken up by a display of w by h pixels.
+	size(w, h int) (int, int)
+
+	// draw draws frame at the top left of screen, in palette.
+	draw(screen tcell.Screen, frame *chip8.Frame, palette [4]tcell.Color)
+}
+
+// renderers are the renderers which can be chosen with --renderer, other than auto.
+var renderers = map[string]func(scale int) renderer{
+	"block":     func(scale int) renderer { return blockRenderer{scale} },
+	"halfblock": func(scale int) renderer { return halfBlockRenderer{scale} },
+	"braille":   func(int) renderer { return brailleRenderer{} },
+}
+
+// chooseRenderer returns the renderer called name for drawing a display of w by h pixels.
+//
+// If name is auto, it returns the half-block renderer if the display fits in cols by rows cells at the given scale,
+// and the braille renderer otherwise. The full-block renderer is never chosen automatically,
+// since its pixels are twice as tall as they are wide.
+func chooseRenderer(name string, scale, cols, rows, w, h int) renderer {
+	if newRenderer, ok := renderers[name]; ok {
+		return newRenderer(scale)
+	}
+	r := halfBlockRenderer{scale}
+	if rw, rh := r.size(w, h); rw <= cols && rh <= rows {
+		return r
+	}
+	return brailleRenderer{}
+}
+
+// blockRenderer draws each pixel as scale by scale full block characters.
+type blockRenderer struct {
+	scale int
+}
+
+func (r blockRenderer) size(w, h int) (int, int) {
+	return w * r.scale, h * r.scale
+}
+
+func (r blockRenderer) draw(screen tcell.Screen, frame *chip8.Frame, palette [4]tcell.Color) {
+	for y := 0; y < frame.Height()*r.scale; y++ {
+		for x := 0; x < frame.Width()*r.scale; x++ {
+			c, brightness := frame.Pixel(x/r.scale, y/r.scale)
+			ch := ' '
+			if brightness > 0 {
+				ch = tcell.RuneBlock
+			}
+			style := tcell.StyleDefault.Foreground(shade(palette, c, brightness)).Background(palette[0])
+			screen.SetContent(x, y, ch, nil, style)
+		}
+	}
+}
+
+// halfBlockRenderer draws two rows of pixels in each row of cells using the upper and lower half block characters,
+// so that pixels are square. Each pixel is scale cells wide and scale half cells tall.
+type halfBlockRenderer struct {
+	scale int
+}
+
+func (r halfBlockRenderer) size(w, h int) (int, int) {
+	return w * r.scale, (h*r.scale + 1) / 2
+}
+
+func (r halfBlockRenderer) draw(screen tcell.Screen, frame *chip8.Frame, palette [4]tcell.Color) {
+	cols, rows := r.size(frame.Width(), frame.Height())
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			top, topBrightness := frame.Pixel(x/r.scale, 2*y/r.scale)
+			var bottom, bottomBrightness uint8
+			if 2*y+1 < frame.Height()*r.scale {
+				bottom, bottomBrightness = frame.Pixel(x/r.scale, (2*y+1)/r.scale)
+			}
+			topColor, bottomColor := shade(palette, top, topBrightness), shade(palette, bottom, bottomBrightness)
+			// an unlit half goes in the background, since colour 1 is usually the terminal's default foreground
+			style := tcell.StyleDefault
+			ch := ' '
+			switch {
+			case topBrightness == 0 && bottomBrightness == 0:
+				style = style.Background(palette[0])
+			case topColor == bottomColor:
+				ch = tcell.RuneBlock
+				style = style.Foreground(topColor).Background(palette[0])
+			case bottomBrightness == 0 || top > bottom:
+				ch = '▀'
+				style = style.Foreground(topColor).Background(bottomColor)
+			default:
+				ch = '▄'
+				style = style.Foreground(bottomColor).Background(topColor)
+			}
+			screen.SetContent(x, y, ch, nil, style)
+		}
+	}
+}
+
+// brailleRenderer draws 2 by 4 pixels in each cell using braille patterns.
+// A cell can only have one foreground colour, so it is drawn in the colour of its brightest pixel,
+// and of the highest colour among those.
+type brailleRenderer struct{}
+
+// brailleDots are the bits of the braille pattern for each pixel in a cell, indexed by row and column.
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+func (brailleRenderer) size(w, h int) (int, int) {
+	return (w + 1) / 2, (h + 3) / 4
+}
+
+func (r brailleRenderer) draw(screen tcell.Screen, frame *chip8.Frame, palette [4]tcell.Color) {
+	cols, rows := r.size(frame.Width(), frame.Height())
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			var dots rune
+			var c, brightness uint8
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					px, py := 2*x+dx, 4*y+dy
+					if px >= frame.Width() || py >= frame.Height() {
+						continue
+					}
+					pc, pb := frame.Pixel(px, py)
+					if pb == 0 {
+						continue
+					}
+					dots |= brailleDots[dy][dx]
+					if pb > brightness || pb == brightness && pc > c {
+						c, brightness = pc, pb
+					}
+				}
+			}
+			ch := ' '
+			if dots != 0 {
+				ch = 0x2800 + dots
+			}
+			style := tcell.StyleDefault.Foreground(shade(palette, c, brightness)).Background(palette[0])
+			screen.SetContent(x, y, ch, nil, style)
+		}
+	}
+}
+
+// shade returns the colour of a pixel in colour c of palette at the given brightness.
+// Pixels which are not fully lit are blended with the background in true colour.
+func shade(palette [4]tcell.Color, c, brightness uint8) tcell.Color {
+	if c == 0 || brightness == chip8.MaxBrightness {
+		return palette[c]
+	}
+	p := imagePalette(palette)
+	blended := chip8.Blend(p[0], p[c], brightness)
+	return tcell.NewRGBColor(int32(blended.R), int32(blended.G), int32(blended.B))
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+var testPalette = [4]tcell.Color{tcell.ColorBlack, tcell.ColorWhite, tcell.ColorRed, tcell.ColorYellow}
+
+// testCell is the content of a cell of the screen.
+type testCell struct {
+	ch     rune
+	fg, bg tcell.Color
+}
+
+// drawCells draws frame with r on a simulated screen and returns the cells at positions.
+func drawCells(t *testing.T, r renderer, frame *chip8.Frame, positions [][2]int) []testCell {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	cols, rows := r.size(frame.Width(), frame.Height())
+	screen.SetSize(cols, rows)
+	r.draw(screen, frame, testPalette)
+	cells := make([]testCell, len(positions))
+	for i, p := range positions {
+		ch, _, style, _ := screen.GetContent(p[0], p[1])
+		fg, bg, _ := style.Decompose()
+		cells[i] = testCell{ch: ch, fg: fg, bg: bg}
+	}
+	return cells
+}
+
+// setPixel lights the pixel at (x, y) of f in colour c at the given brightness.
+func setPixel(f *chip8.Frame, x, y int, c, brightness uint8) {
+	f.Colors[y][x] = c
+	f.Brightness[y][x] = brightness
+}
+
+func TestChooseRenderer(t *testing.T) {
+	tests := []struct {
+		name             string
+		renderer         string
+		scale            int
+		cols, rows, w, h int
+		want             renderer
+	}{
+		{
+			name:     "block",
+			renderer: "block",
+			scale:    2,
+			want:     blockRenderer{2},
+		},
+		{
+			name:     "halfblock",
+			renderer: "halfblock",
+			scale:    3,
+			want:     halfBlockRenderer{3},
+		},
+		{
+			name:     "braille",
+			renderer: "braille",
+			scale:    2,
+			want:     brailleRenderer{},
+		},
+		{
+			name:     "auto fits exactly",
+			renderer: "auto",
+			scale:    1,
+			cols:     64, rows: 16, w: 64, h: 32,
+			want: halfBlockRenderer{1},
+		},
+		{
+			name:     "auto too narrow",
+			renderer: "auto",
+			scale:    1,
+			cols:     63, rows: 16, w: 64, h: 32,
+			want: brailleRenderer{},
+		},
+		{
+			name:     "auto too short",
+			renderer: "auto",
+			scale:    1,
+			cols:     80, rows: 15, w: 64, h: 32,
+			want: brailleRenderer{},
+		},
+		{
+			name:     "auto scaled",
+			renderer: "auto",
+			scale:    2,
+			cols:     128, rows: 32, w: 64, h: 32,
+			want: halfBlockRenderer{2},
+		},
+		{
+			name:     "auto hires",
+			renderer: "auto",
+			scale:    1,
+			cols:     100, rows: 40, w: 128, h: 64,
+			want: brailleRenderer{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chooseRenderer(tt.renderer, tt.scale, tt.cols, tt.rows, tt.w, tt.h)
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(blockRenderer{}, halfBlockRenderer{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestRenderer_size(t *testing.T) {
+	tests := []struct {
+		name         string
+		renderer     renderer
+		w, h         int
+		wantW, wantH int
+	}{
+		{"block", blockRenderer{2}, 64, 32, 128, 64},
+		{"halfblock", halfBlockRenderer{1}, 64, 32, 64, 16},
+		{"halfblock odd height", halfBlockRenderer{1}, 64, 31, 64, 16},
+		{"halfblock odd scale", halfBlockRenderer{3}, 64, 31, 192, 47},
+		{"braille", brailleRenderer{}, 128, 64, 64, 16},
+		{"braille partial cells", brailleRenderer{}, 63, 30, 32, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w, h := tt.renderer.size(tt.w, tt.h); w != tt.wantW || h != tt.wantH {
+				t.Errorf("want = %dx%d, got = %dx%d", tt.wantW, tt.wantH, w, h)
+			}
+		})
+	}
+}
+
+func TestHalfBlockRenderer_draw(t *testing.T) {
+	const half = chip8.MaxBrightness / 2
+	var frame chip8.Frame
+	setPixel(&frame, 1, 0, 1, chip8.MaxBrightness) // both halves lit in the same colour
+	setPixel(&frame, 1, 1, 1, chip8.MaxBrightness)
+	setPixel(&frame, 2, 0, 1, chip8.MaxBrightness) // top half only
+	setPixel(&frame, 3, 1, 1, chip8.MaxBrightness) // bottom half only
+	setPixel(&frame, 4, 0, 2, chip8.MaxBrightness) // higher colour on top
+	setPixel(&frame, 4, 1, 1, chip8.MaxBrightness)
+	setPixel(&frame, 5, 0, 1, chip8.MaxBrightness) // higher colour below
+	setPixel(&frame, 5, 1, 2, chip8.MaxBrightness)
+	setPixel(&frame, 6, 0, 1, half) // same colour at different brightnesses
+	setPixel(&frame, 6, 1, 1, chip8.MaxBrightness)
+	setPixel(&frame, 7, 31, 3, chip8.MaxBrightness) // bottom row of the display
+
+	got := drawCells(t, halfBlockRenderer{1}, &frame, [][2]int{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 0}, {7, 15}})
+	want := []testCell{
+		{' ', tcell.ColorDefault, testPalette[0]},
+		{tcell.RuneBlock, testPalette[1], testPalette[0]},
+		{'▀', testPalette[1], testPalette[0]},
+		{'▄', testPalette[1], testPalette[0]},
+		{'▀', testPalette[2], testPalette[1]},
+		{'▄', testPalette[2], testPalette[1]},
+		{'▄', testPalette[1], shade(testPalette, 1, half)},
+		{'▄', testPalette[3], testPalette[0]},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(testCell{})); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestHalfBlockRenderer_draw_scale(t *testing.T) {
+	var frame chip8.Frame
+	setPixel(&frame, 0, 0, 1, chip8.MaxBrightness)
+	setPixel(&frame, 1, 1, 2, chip8.MaxBrightness)
+
+	// at scale 3, pixel (0, 0) covers three columns and a row and a half of cells
+	got := drawCells(t, halfBlockRenderer{3}, &frame, [][2]int{{0, 0}, {2, 0}, {2, 1}, {3, 1}, {3, 2}, {6, 1}})
+	want := []testCell{
+		{tcell.RuneBlock, testPalette[1], testPalette[0]},
+		{tcell.RuneBlock, testPalette[1], testPalette[0]},
+		{'▀', testPalette[1], testPalette[0]},
+		{'▄', testPalette[2], testPalette[0]},
+		{tcell.RuneBlock, testPalette[2], testPalette[0]},
+		{' ', tcell.ColorDefault, testPalette[0]},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(testCell{})); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestBrailleRenderer_draw(t *testing.T) {
+	const half, quarter = chip8.MaxBrightness / 2, chip8.MaxBrightness / 4
+	var frame chip8.Frame
+	// the first cell has the top left and bottom right dots, and takes the higher colour
+	setPixel(&frame, 0, 0, 1, chip8.MaxBrightness)
+	setPixel(&frame, 1, 3, 2, chip8.MaxBrightness)
+	// the second cell takes the colour of its brightest pixel
+	setPixel(&frame, 2, 1, 1, half)
+	setPixel(&frame, 3, 2, 2, quarter)
+	// the last cell of the display
+	setPixel(&frame, 63, 31, 3, chip8.MaxBrightness)
+
+	got := drawCells(t, brailleRenderer{}, &frame, [][2]int{{0, 0}, {1, 0}, {2, 0}, {31, 7}})
+	want := []testCell{
+		{0x2800 + 0x01 + 0x80, testPalette[2], testPalette[0]},
+		{0x2800 + 0x02 + 0x20, shade(testPalette, 1, half), testPalette[0]},
+		{' ', testPalette[0], testPalette[0]},
+		{0x2800 + 0x80, testPalette[3], testPalette[0]},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(testCell{})); diff != "" {
+		t.Error(diff)
+	}
+}
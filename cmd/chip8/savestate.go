@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/yi-jiayu/chip8"
+)
+
+// numSaveSlots is the number of save state slots.
+const numSaveSlots = 4
+
+// Slots are saved with F1 to F4 and loaded with F5 to F8.
+const (
+	keySave = tcell.KeyF1
+	keyLoad = tcell.KeyF5
+)
+
+func saveSlotPath(slot int) string {
+	return fmt.Sprintf("chip8-slot%d.state", slot)
+}
+
+// saveState writes a snapshot of ip to a numbered save slot.
+func saveState(ip *chip8.Interpreter, slot int) error {
+	data, err := ip.Snapshot().MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(saveSlotPath(slot), data, 0644)
+}
+
+// loadState restores ip from a numbered save slot.
+func loadState(ip *chip8.Interpreter, slot int) error {
+	data, err := ioutil.ReadFile(saveSlotPath(slot))
+	if err != nil {
+		return err
+	}
+	var s chip8.Snapshot
+	if err := s.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	ip.Restore(&s)
+	return nil
+}
+
+// handleSaveStateKey saves or loads a save state if key is one of the save state hotkeys,
+// and reports whether it was.
+func handleSaveStateKey(m *machine, key tcell.Key) bool {
+	switch {
+	case key >= keySave && key < keySave+numSaveSlots:
+		slot := int(key-keySave) + 1
+		m.do(func(ip *chip8.Interpreter) {
+			if err := saveState(ip, slot); err != nil {
+				log.Printf("saving slot %d: %v", slot, err)
+			}
+		})
+	case key >= keyLoad && key < keyLoad+numSaveSlots:
+		slot := int(key-keyLoad) + 1
+		m.do(func(ip *chip8.Interpreter) {
+			if err := loadState(ip, slot); err != nil {
+				log.Printf("loading slot %d: %v", slot, err)
+			}
+		})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,237 @@
+package chip8_test
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yi-jiayu/chip8"
+	"github.com/yi-jiayu/chip8/asm"
+)
+
+var update = flag.Bool("update", false, "update the golden screens in testdata/golden")
+
+// conformanceInstructionsPerFrame is high enough for the test ROMs to tell apart
+// drawing with and without the DisplayWait quirk.
+const conformanceInstructionsPerFrame = 100
+
+// platformAddr is the address which the community test suite reads to choose a platform without showing its menu.
+const platformAddr = 0x1FF
+
+// conformanceROMs are the community test ROMs vendored in testdata/roms/community.
+// Their expected screens in testdata/reference were taken from a reference interpreter, so -update never writes them.
+var conformanceROMs = []struct {
+	file   string
+	frames int
+
+	// platforms maps the quirks profiles the ROM is run with to the value written to platformAddr before running it,
+	// or to 0 to leave memory as loaded. If it is nil, the ROM is run with every profile.
+	platforms map[string]uint8
+
+	// keys holds the state of the keypad from each frame onwards.
+	keys map[int]uint16
+}{
+	{file: "3-corax+.ch8", frames: 120},
+	{file: "4-flags.ch8", frames: 120},
+	{
+		file:      "5-quirks.ch8",
+		frames:    600,
+		platforms: map[string]uint8{"vip": 1, "schip": 2, "xochip": 3},
+	},
+	{
+		file:      "6-keypad.ch8",
+		frames:    60,
+		platforms: map[string]uint8{"vip": 3, "chip48": 3, "schip": 3, "xochip": 3},
+		keys:      map[int]uint16{10: 1 << 0xA, 20: 0},
+	},
+}
+
+// regressionROMs are the test ROMs written in Octo for this repository in testdata/roms. Their golden screens in
+// testdata/golden are the interpreter's own earlier output, so they catch changes in behaviour but do not show that
+// the behaviour is correct.
+var regressionROMs = []struct {
+	file   string
+	frames int
+
+	// keys holds the state of the keypad from each frame onwards.
+	keys map[int]uint16
+}{
+	{file: "opcodes.8o", frames: 120},
+	{file: "flags.8o", frames: 120},
+	{file: "quirks.8o", frames: 120},
+	{
+		file:   "keypad.8o",
+		frames: 60,
+		keys:   map[int]uint16{10: 1 << 0xA, 20: 0, 30: 1 << 5, 40: 0},
+	},
+}
+
+var conformanceProfiles = []struct {
+	name   string
+	quirks chip8.Quirks
+}{
+	{"vip", chip8.QuirksCOSMACVIP},
+	{"chip48", chip8.QuirksCHIP48},
+	{"schip", chip8.QuirksSUPERCHIP},
+	{"xochip", chip8.QuirksXOCHIP},
+}
+
+// TestConformance runs each community test ROM with each quirks profile and compares the final display with the
+// screen shown by a reference interpreter in testdata/reference. ROMs which have not been vendored are skipped.
+func TestConformance(t *testing.T) {
+	for _, rom := range conformanceROMs {
+		name := strings.TrimSuffix(rom.file, filepath.Ext(rom.file))
+		prog, err := loadROM(filepath.Join("testdata", "roms", "community", rom.file))
+		for _, profile := range conformanceProfiles {
+			rom, profile := rom, profile
+			t.Run(name+"/"+profile.name, func(t *testing.T) {
+				if os.IsNotExist(err) {
+					t.Skipf("%s is not vendored, see testdata/README.md", rom.file)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				platform, ok := rom.platforms[profile.name]
+				if rom.platforms != nil && !ok {
+					t.Skipf("%s has no platform for this profile", rom.file)
+				}
+				want, err := ioutil.ReadFile(filepath.Join("testdata", "reference", name+"-"+profile.name+".txt"))
+				if os.IsNotExist(err) {
+					t.Skip("no reference screen, see testdata/README.md")
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := runROM(prog, profile.quirks, platform, rom.frames, rom.keys)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := diffScreens(string(want), got); diff != "" {
+					t.Errorf("display mismatch (- want, + got, ^ differing pixels):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+// TestRegressionScreens runs each test ROM written for this repository with each quirks profile and compares the
+// final display with the golden screen in testdata/golden. Run with -update to write the golden screens from the
+// current output.
+func TestRegressionScreens(t *testing.T) {
+	for _, rom := range regressionROMs {
+		prog, err := loadROM(filepath.Join("testdata", "roms", rom.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := strings.TrimSuffix(rom.file, filepath.Ext(rom.file))
+		for _, profile := range conformanceProfiles {
+			rom, profile := rom, profile
+			t.Run(name+"/"+profile.name, func(t *testing.T) {
+				got, err := runROM(prog, profile.quirks, 0, rom.frames, rom.keys)
+				if err != nil {
+					t.Fatal(err)
+				}
+				golden := filepath.Join("testdata", "golden", name+"-"+profile.name+".txt")
+				if *update {
+					if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := ioutil.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := diffScreens(string(want), got); diff != "" {
+					t.Errorf("display mismatch (- want, + got, ^ differing pixels):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+// loadROM reads the ROM at path, assembling it first if it is Octo source.
+func loadROM(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(path) != ".8o" {
+		return b, nil
+	}
+	prog, err := asm.Assemble(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return prog, nil
+}
+
+// runROM runs prog for a number of frames, setting the state of the keypad from keys at the start of each frame,
+// and returns the final display. If platform is not 0, it is written to platformAddr before running.
+func runROM(prog []byte, quirks chip8.Quirks, platform uint8, frames int, keys map[int]uint16) (string, error) {
+	var keypad scriptedKeypad
+	ip := chip8.New(&keypad, nil, quirks)
+	ip.SetInstructionsPerFrame(conformanceInstructionsPerFrame)
+	if err := ip.Load(prog); err != nil {
+		return "", err
+	}
+	if platform != 0 {
+		s := ip.Snapshot()
+		s.Memory[platformAddr] = platform
+		ip.Restore(s)
+	}
+	for frame := 0; frame < frames && !ip.Exited(); frame++ {
+		if state, ok := keys[frame]; ok {
+			keypad = scriptedKeypad(state)
+		}
+		if err := ip.RunFrame(); err != nil {
+			return "", err
+		}
+	}
+	d := ip.Display()
+	return d.String(), nil
+}
+
+// scriptedKeypad is a keypad whose keys are set by the test.
+type scriptedKeypad uint16
+
+func (k *scriptedKeypad) Keys() uint16 {
+	return uint16(*k)
+}
+
+// diffScreens returns the rows which differ between the screens want and got, with the differing pixels marked
+// underneath, or an empty string if they are the same.
+func diffScreens(want, got string) string {
+	wantRows := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	gotRows := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	var b strings.Builder
+	if len(wantRows) != len(gotRows) {
+		fmt.Fprintf(&b, "want %d rows, got %d rows\n", len(wantRows), len(gotRows))
+	}
+	for y := 0; y < len(wantRows) || y < len(gotRows); y++ {
+		var w, g string
+		if y < len(wantRows) {
+			w = wantRows[y]
+		}
+		if y < len(gotRows) {
+			g = gotRows[y]
+		}
+		if w == g {
+			continue
+		}
+		marks := make([]byte, 0, len(w))
+		for x := 0; x < len(w) || x < len(g); x++ {
+			if x < len(w) && x < len(g) && w[x] == g[x] {
+				marks = append(marks, ' ')
+			} else {
+				marks = append(marks, '^')
+			}
+		}
+		fmt.Fprintf(&b, "- %2d %s\n+ %2d %s\n     %s\n", y, w, y, g, strings.TrimRight(string(marks), " "))
+	}
+	return b.String()
+}
@@ -0,0 +1,207 @@
+package chip8
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mnemonics contains the assembly syntax for each opcode. Operands are written as {x}, {y}, {n}, {kk}, {nnn}
+// and {nnnn} for the long address following F000.
+var mnemonics = [...]string{
+	OpCLS_00E0:   "CLS",
+	OpRET_00EE:   "RET",
+	OpSYS_0nnn:   "SYS {nnn}",
+	OpJP_1nnn:    "JP {nnn}",
+	OpCALL_2nnn:  "CALL {nnn}",
+	OpSE_3xkk:    "SE V{x}, {kk}",
+	OpSNE_4xkk:   "SNE V{x}, {kk}",
+	OpSE_5xy0:    "SE V{x}, V{y}",
+	OpLD_6xkk:    "LD V{x}, {kk}",
+	OpADD_7xkk:   "ADD V{x}, {kk}",
+	OpLD_8xy0:    "LD V{x}, V{y}",
+	OpOR_8xy1:    "OR V{x}, V{y}",
+	OpAND_8xy2:   "AND V{x}, V{y}",
+	OpXOR_8xy3:   "XOR V{x}, V{y}",
+	OpADD_8xy4:   "ADD V{x}, V{y}",
+	OpSUB_8xy5:   "SUB V{x}, V{y}",
+	OpSHR_8xy6:   "SHR V{x}, V{y}",
+	OpSUBN_8xy7:  "SUBN V{x}, V{y}",
+	OpSHL_8xyE:   "SHL V{x}, V{y}",
+	OpSNE_9xy0:   "SNE V{x}, V{y}",
+	OpLD_Annn:    "LD I, {nnn}",
+	OpJP_Bnnn:    "JP V0, {nnn}",
+	OpRND_Cxkk:   "RND V{x}, {kk}",
+	OpDRW_Dxyn:   "DRW V{x}, V{y}, {n}",
+	OpSKP_Ex9E:   "SKP V{x}",
+	OpSKNP_ExA1:  "SKNP V{x}",
+	OpLD_Fx07:    "LD V{x}, DT",
+	OpLD_Fx0A:    "LD V{x}, K",
+	OpLD_Fx15:    "LD DT, V{x}",
+	OpLD_Fx18:    "LD ST, V{x}",
+	OpADD_Fx1E:   "ADD I, V{x}",
+	OpLD_Fx29:    "LD F, V{x}",
+	OpLD_Fx33:    "LD B, V{x}",
+	OpLD_Fx55:    "LD [I], V{x}",
+	OpLD_Fx65:    "LD V{x}, [I]",
+	OpSCD_00Cn:   "SCD {n}",
+	OpSCR_00FB:   "SCR",
+	OpSCL_00FC:   "SCL",
+	OpEXIT_00FD:  "EXIT",
+	OpLOW_00FE:   "LOW",
+	OpHIGH_00FF:  "HIGH",
+	OpLD_Fx30:    "LD HF, V{x}",
+	OpLD_Fx75:    "LD R, V{x}",
+	OpLD_Fx85:    "LD V{x}, R",
+	OpSCU_00Dn:   "SCU {n}",
+	OpSAVE_5xy2:  "SAVE V{x} - V{y}",
+	OpLOAD_5xy3:  "LOAD V{x} - V{y}",
+	OpLD_F000:    "LD I, LONG {nnnn}",
+	OpPLANE_Fn01: "PLANE {x}",
+	OpAUDIO_F002: "AUDIO",
+	OpPITCH_Fx3A: "PITCH V{x}",
+}
+
+// Disassemble returns the assembly for the instruction at addr in mem, and the length of the instruction in bytes.
+// Words which are not valid instructions are disassembled as DW directives.
+func Disassemble(mem []uint8, addr int) (string, int) {
+	return disassemble(mem, addr, nil)
+}
+
+// disassemble is like Disassemble, but writes addresses which have a label in labels using the label instead.
+func disassemble(mem []uint8, addr int, labels map[int]string) (string, int) {
+	if addr+instrLen > len(mem) {
+		if addr < len(mem) {
+			return fmt.Sprintf("DB 0x%02X", mem[addr]), 1
+		}
+		return "", 0
+	}
+	instr := instruction{hi: mem[addr], lo: mem[addr+1]}
+	op := instr.opcode()
+	if op == OpIllegal {
+		return fmt.Sprintf("DW 0x%04X", instr.word()), instrLen
+	}
+	length := instrLen
+	var long string
+	if op == OpLD_F000 {
+		if addr+2*instrLen > len(mem) {
+			return fmt.Sprintf("DW 0x%04X", instr.word()), instrLen
+		}
+		long = fmt.Sprintf("0x%02X%02X", mem[addr+2], mem[addr+3])
+		length = 2 * instrLen
+	}
+	nnn, ok := labels[int(instr.addr())]
+	if !ok {
+		nnn = fmt.Sprintf("0x%03X", instr.addr())
+	}
+	r := strings.NewReplacer(
+		"{x}", fmt.Sprintf("%X", instr.x()),
+		"{y}", fmt.Sprintf("%X", instr.y()),
+		"{n}", fmt.Sprintf("%d", instr.nibble()),
+		"{kk}", fmt.Sprintf("0x%02X", instr.byte()),
+		"{nnn}", nnn,
+		"{nnnn}", long,
+	)
+	return r.Replace(mnemonics[op]), length
+}
+
+// maxDataLineLen is the maximum number of data bytes on one line of a listing.
+const maxDataLineLen = 8
+
+// Line is a single line of a disassembly listing.
+type Line struct {
+	// Addr is the address of the first byte on the line.
+	Addr int
+
+	// Bytes are the raw bytes disassembled on the line.
+	Bytes []uint8
+
+	// Label is the name of the line if it is the target of a jump or call.
+	Label string
+
+	// Asm is the disassembled instruction, or a DB directive for data.
+	Asm string
+}
+
+// DisassembleProgram disassembles a program which is loaded at 0x200.
+//
+// The code which is reachable from 0x200 is found by following jumps, calls and skips.
+// Everything else is assumed to be data, such as sprites, and is disassembled as DB directives.
+// Jump and call targets are given labels, which are used as the operands of the instructions which refer to them.
+//
+// Implementation note: the targets of JP V0, nnn depend on the value of V0 at run time,
+// so only nnn itself is followed.
+func DisassembleProgram(prog []uint8) []Line {
+	mem := make([]uint8, memoryOffsetProgram+len(prog))
+	copy(mem[memoryOffsetProgram:], prog)
+
+	// find the start of every reachable instruction
+	code := make(map[int]bool)
+	labels := make(map[int]string)
+	queue := []int{memoryOffsetProgram}
+	for len(queue) > 0 {
+		addr := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for addr >= memoryOffsetProgram && addr+instrLen <= len(mem) && !code[addr] {
+			instr := instruction{hi: mem[addr], lo: mem[addr+1]}
+			op := instr.opcode()
+			if op == OpIllegal {
+				break
+			}
+			code[addr] = true
+			next := addr + instrLen
+			if op == OpLD_F000 {
+				next += instrLen
+			}
+			switch op {
+			case OpRET_00EE, OpEXIT_00FD:
+				next = -1
+			case OpJP_1nnn, OpJP_Bnnn:
+				labels[int(instr.addr())] = ""
+				queue = append(queue, int(instr.addr()))
+				next = -1
+			case OpCALL_2nnn:
+				labels[int(instr.addr())] = ""
+				queue = append(queue, int(instr.addr()))
+			case OpSE_3xkk, OpSNE_4xkk, OpSE_5xy0, OpSNE_9xy0, OpSKP_Ex9E, OpSKNP_ExA1:
+				// the skipped instruction is four bytes long if it is LD I, LONG nnnn
+				skip := next + instrLen
+				if next+1 < len(mem) && mem[next] == 0xF0 && mem[next+1] == 0x00 {
+					skip += instrLen
+				}
+				queue = append(queue, skip)
+			}
+			addr = next
+		}
+	}
+	for addr := range labels {
+		if code[addr] {
+			labels[addr] = fmt.Sprintf("L%03X", addr)
+		} else {
+			delete(labels, addr)
+		}
+	}
+
+	var lines []Line
+	for addr := memoryOffsetProgram; addr < len(mem); {
+		line := Line{Addr: addr, Label: labels[addr]}
+		if code[addr] {
+			var n int
+			line.Asm, n = disassemble(mem, addr, labels)
+			line.Bytes = mem[addr : addr+n]
+		} else {
+			end := addr + 1
+			for end < len(mem) && end-addr < maxDataLineLen && !code[end] {
+				end++
+			}
+			line.Bytes = mem[addr:end]
+			operands := make([]string, len(line.Bytes))
+			for i, b := range line.Bytes {
+				operands[i] = fmt.Sprintf("0x%02X", b)
+			}
+			line.Asm = "DB " + strings.Join(operands, ", ")
+		}
+		lines = append(lines, line)
+		addr += len(line.Bytes)
+	}
+	return lines
+}
@@ -0,0 +1,92 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDisassemble(t *testing.T) {
+	tests := []struct {
+		name    string
+		mem     []uint8
+		want    string
+		wantLen int
+	}{
+		{
+			name:    "no operands",
+			mem:     []uint8{0x00, 0xE0},
+			want:    "CLS",
+			wantLen: 2,
+		},
+		{
+			name:    "register and byte",
+			mem:     []uint8{0x63, 0x1F},
+			want:    "LD V3, 0x1F",
+			wantLen: 2,
+		},
+		{
+			name:    "two registers and nibble",
+			mem:     []uint8{0xD0, 0x15},
+			want:    "DRW V0, V1, 5",
+			wantLen: 2,
+		},
+		{
+			name:    "address",
+			mem:     []uint8{0x22, 0x4A},
+			want:    "CALL 0x24A",
+			wantLen: 2,
+		},
+		{
+			name:    "long address",
+			mem:     []uint8{0xF0, 0x00, 0x12, 0x34},
+			want:    "LD I, LONG 0x1234",
+			wantLen: 4,
+		},
+		{
+			name:    "illegal instruction",
+			mem:     []uint8{0xFF, 0xFF},
+			want:    "DW 0xFFFF",
+			wantLen: 2,
+		},
+		{
+			name:    "odd byte at end of memory",
+			mem:     []uint8{0xAB},
+			want:    "DB 0xAB",
+			wantLen: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotLen := Disassemble(tt.mem, 0)
+			if got != tt.want || gotLen != tt.wantLen {
+				t.Errorf("Disassemble() = %q, %d, want %q, %d", got, gotLen, tt.want, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestDisassembleProgram(t *testing.T) {
+	prog := []uint8{
+		0x22, 0x08, // 0200: CALL 0x208
+		0x30, 0x01, // 0202: SE V0, 0x01
+		0x12, 0x06, // 0204: JP 0x206
+		0x00, 0xFD, // 0206: EXIT
+		0xA2, 0x0C, // 0208: LD I, 0x20C
+		0x00, 0xEE, // 020A: RET
+		0xF0, 0x90, 0xF0, // 020C: sprite data
+	}
+	expected := []Line{
+		{Addr: 0x200, Bytes: []uint8{0x22, 0x08}, Asm: "CALL L208"},
+		{Addr: 0x202, Bytes: []uint8{0x30, 0x01}, Asm: "SE V0, 0x01"},
+		{Addr: 0x204, Bytes: []uint8{0x12, 0x06}, Asm: "JP L206"},
+		{Addr: 0x206, Bytes: []uint8{0x00, 0xFD}, Label: "L206", Asm: "EXIT"},
+		{Addr: 0x208, Bytes: []uint8{0xA2, 0x0C}, Label: "L208", Asm: "LD I, 0x20C"},
+		{Addr: 0x20A, Bytes: []uint8{0x00, 0xEE}, Asm: "RET"},
+		{Addr: 0x20C, Bytes: []uint8{0xF0, 0x90, 0xF0}, Asm: "DB 0xF0, 0x90, 0xF0"},
+	}
+	actual := DisassembleProgram(prog)
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("DisassembleProgram() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,199 @@
+package chip8
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Display resolutions for the standard Chip-8 low resolution mode and the SUPER-CHIP high resolution mode.
+const (
+	DisplayWidthLores  = 64
+	DisplayHeightLores = 32
+	DisplayWidthHires  = 128
+	DisplayHeightHires = 64
+)
+
+// Plane is a single monochrome bitplane of a Chip-8 framebuffer.
+//
+// Pixels are stored one bit per pixel, with the most significant bit of each byte being the leftmost pixel.
+// A plane is always large enough to hold a high resolution display; in low resolution mode only the
+// top-left 64x32 pixels are used.
+type Plane [DisplayHeightHires][DisplayWidthHires / 8]uint8
+
+// Pixel reports whether the pixel at (x, y) is set.
+func (p *Plane) Pixel(x, y int) bool {
+	return p[y][x>>3]&(0x80>>uint(x&7)) > 0
+}
+
+func (p *Plane) set(x, y int, on bool) {
+	mask := uint8(0x80) >> uint(x&7)
+	if on {
+		p[y][x>>3] |= mask
+	} else {
+		p[y][x>>3] &^= mask
+	}
+}
+
+// flip toggles the pixel at (x, y) and reports whether it was previously set.
+func (p *Plane) flip(x, y int) bool {
+	mask := uint8(0x80) >> uint(x&7)
+	collision := p[y][x>>3]&mask > 0
+	p[y][x>>3] ^= mask
+	return collision
+}
+
+// Display is a Chip-8 framebuffer.
+//
+// The display is made up of two bitplanes. Standard Chip-8 and SUPER-CHIP programs only ever draw to the first
+// plane, while XO-CHIP programs can select either or both planes with the PLANE instruction to draw in four colours.
+type Display struct {
+	// Hires is true when the display is in the SUPER-CHIP 128x64 mode.
+	Hires bool
+
+	Planes [2]Plane
+}
+
+// Width returns the width of the display in pixels at the current resolution.
+func (d *Display) Width() int {
+	if d.Hires {
+		return DisplayWidthHires
+	}
+	return DisplayWidthLores
+}
+
+// Height returns the height of the display in pixels at the current resolution.
+func (d *Display) Height() int {
+	if d.Hires {
+		return DisplayHeightHires
+	}
+	return DisplayHeightLores
+}
+
+// Pixel returns the colour of the pixel at (x, y), from 0 to 3.
+// Bit 0 of the colour is set if the pixel is set in the first plane, and bit 1 if it is set in the second plane.
+func (d *Display) Pixel(x, y int) uint8 {
+	var c uint8
+	for i := range d.Planes {
+		if d.Planes[i].Pixel(x, y) {
+			c |= 1 << uint(i)
+		}
+	}
+	return c
+}
+
+// clear blanks the planes selected by mask.
+func (d *Display) clear(mask uint8) {
+	for i := range d.Planes {
+		if mask&(1<<uint(i)) > 0 {
+			d.Planes[i] = Plane{}
+		}
+	}
+}
+
+// scrollDown moves the contents of the planes selected by mask down by n rows, filling the top with blank rows.
+func (d *Display) scrollDown(mask uint8, n int) {
+	h := d.Height()
+	for i := range d.Planes {
+		if mask&(1<<uint(i)) == 0 {
+			continue
+		}
+		p := &d.Planes[i]
+		for y := h - 1; y >= 0; y-- {
+			if y >= n {
+				p[y] = p[y-n]
+			} else {
+				p[y] = [DisplayWidthHires / 8]uint8{}
+			}
+		}
+	}
+}
+
+// scrollUp moves the contents of the planes selected by mask up by n rows, filling the bottom with blank rows.
+func (d *Display) scrollUp(mask uint8, n int) {
+	h := d.Height()
+	for i := range d.Planes {
+		if mask&(1<<uint(i)) == 0 {
+			continue
+		}
+		p := &d.Planes[i]
+		for y := 0; y < h; y++ {
+			if y+n < h {
+				p[y] = p[y+n]
+			} else {
+				p[y] = [DisplayWidthHires / 8]uint8{}
+			}
+		}
+	}
+}
+
+// scrollRight moves the contents of the planes selected by mask right by n pixels, filling the left with blank pixels.
+func (d *Display) scrollRight(mask uint8, n int) {
+	w, h := d.Width(), d.Height()
+	for i := range d.Planes {
+		if mask&(1<<uint(i)) == 0 {
+			continue
+		}
+		p := &d.Planes[i]
+		for y := 0; y < h; y++ {
+			for x := w - 1; x >= 0; x-- {
+				p.set(x, y, x >= n && p.Pixel(x-n, y))
+			}
+		}
+	}
+}
+
+// scrollLeft moves the contents of the planes selected by mask left by n pixels, filling the right with blank pixels.
+func (d *Display) scrollLeft(mask uint8, n int) {
+	w, h := d.Width(), d.Height()
+	for i := range d.Planes {
+		if mask&(1<<uint(i)) == 0 {
+			continue
+		}
+		p := &d.Planes[i]
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				p.set(x, y, x+n < w && p.Pixel(x+n, y))
+			}
+		}
+	}
+}
+
+// displayRunes are the characters which Display.String uses for each of the four colours.
+const displayRunes = ".#+@"
+
+// String returns the display as text, with one line per row and one character per pixel.
+// Colour 0 is drawn as '.', colour 1 as '#', colour 2 as '+' and colour 3 as '@'.
+func (d *Display) String() string {
+	var b strings.Builder
+	w, h := d.Width(), d.Height()
+	b.Grow((w + 1) * h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			b.WriteByte(displayRunes[d.Pixel(x, y)])
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// DefaultPalette draws the background black, the first plane white, the second plane red and both planes gold.
+var DefaultPalette = color.Palette{
+	color.Black,
+	color.White,
+	color.RGBA{R: 0xFF, G: 0x45, A: 0xFF},
+	color.RGBA{R: 0xFF, G: 0xD7, A: 0xFF},
+}
+
+// Image returns the display as an image with one pixel per pixel, using palette for the four colours.
+// The palette must have at least four colours.
+func (d *Display) Image(palette color.Palette) *image.Paletted {
+	w, h := d.Width(), d.Height()
+	img := image.NewPaletted(image.Rect(0, 0, w, h), palette)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetColorIndex(x, y, d.Pixel(x, y))
+		}
+	}
+	return img
+}
@@ -0,0 +1,37 @@
+package chip8
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplay_String(t *testing.T) {
+	var d Display
+	d.Planes[0].set(0, 0, true)
+	d.Planes[1].set(1, 0, true)
+	d.Planes[0].set(2, 0, true)
+	d.Planes[1].set(2, 0, true)
+	lines := strings.Split(d.String(), "\n")
+	if len(lines) != DisplayHeightLores+1 {
+		t.Fatalf("want %d lines, got %d", DisplayHeightLores+1, len(lines))
+	}
+	want := "#+@" + strings.Repeat(".", DisplayWidthLores-3)
+	if lines[0] != want {
+		t.Errorf("want = %q, got = %q", want, lines[0])
+	}
+}
+
+func TestDisplay_Image(t *testing.T) {
+	d := Display{Hires: true}
+	d.Planes[1].set(127, 63, true)
+	img := d.Image(DefaultPalette)
+	if got := img.Bounds().Size(); got.X != DisplayWidthHires || got.Y != DisplayHeightHires {
+		t.Errorf("size: want = %dx%d, got = %v", DisplayWidthHires, DisplayHeightHires, got)
+	}
+	if got := img.ColorIndexAt(127, 63); got != 2 {
+		t.Errorf("colour: want = 2, got = %d", got)
+	}
+	if got := img.ColorIndexAt(0, 0); got != 0 {
+		t.Errorf("colour: want = 0, got = %d", got)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Package chip8 implements a Chip-8 interpreter with support for the SUPER-CHIP and XO-CHIP extensions.
+
+An interpreter is created with New, which takes a Keypad to read the state of the keys from,
+a channel to send the contents of the display to at the end of each 60 Hz frame in which it changed,
+and the quirks to use for instructions whose behaviour differs between interpreters. NewKeyboard returns
+a keypad which is driven by key presses and releases from a keyboard:
+
+	keyboard := chip8.NewKeyboard(chip8.NewKeymap(chip8.QwertyLayout))
+	display := make(chan chip8.Display)
+	ip := chip8.New(keyboard, display, chip8.QuirksXOCHIP)
+
+Keys stay down until they are released. If the keyboard has never reported releasing them, a key stays down for
+RepeatDelay after it is first pressed and for HoldTime after each key repeat, so that a terminal's key repeats hold
+a key down.
+
+A program is loaded with Load, and then run in real time with Run until it is stopped with Stop:
+
+	if err := ip.Load(prog); err != nil {
+		// the program is too large for the memory given by the quirks
+	}
+	go func() {
+		if err := ip.Run(); err != nil {
+			// handle err
+		}
+	}()
+	for d := range display {
+		// draw d
+	}
+
+LoadAt loads a program at an address other than 0x200, such as 0x600 for ETI-660 programs.
+
+Alternatively, an interpreter can be driven synchronously without depending on wall-clock time.
+Step executes a single instruction, while RunFrame executes the instructions for one 60 Hz frame
+and then decrements the delay and sound timers. SetFrameFunc sets a function to be called at the end of
+every frame. In between, the state of the interpreter can be inspected with methods such as Registers, PC
+and Display. A Display can be rendered as text with String or as an image with Image.
+
+A Filter turns each display into the Frame shown on screen, in which pixels can be partly lit.
+NewPersistence keeps pixels lit as they fade out like the phosphor of a CRT, and Merge combines each display
+with the previous one, both of which reduce the flicker of sprites which are erased and redrawn.
+
+RND draws from a random number generator seeded from the time, unless it is seeded with SetSeed or given another
+Random source with SetRandom, such as a FixedRandom sequence of bytes for tests, or VIPRandom, which emulates
+the COSMAC VIP.
+
+A Movie records the keys held down in each frame of a run together with the program, quirks and seed, so that the
+run can be replayed exactly: a MovieRecorder records the keys of another Keypad and a MoviePlayer plays them back,
+both sampling the keys on frame boundaries.
+
+SetAudio sets an Audio which is told the Sound whenever it changes: when the sound timer becomes nonzero or reaches
+zero, or an XO-CHIP program loads a new pattern or pitch. Synth is an Audio which turns the sound into PCM samples.
+
+If the program executes an instruction that cannot be carried out, such as an unknown opcode or a return
+from a subroutine with an empty stack, the interpreter stops and Step, RunFrame and Run return a *Fault
+recording the kind of fault and the instruction which caused it.
+
+Disassemble and DisassembleProgram turn machine code back into assembly, and Encode encodes instructions
+using the same opcode table as the interpreter. The asm package assembles programs written in Octo syntax.
+
+The cmd/chip8 directory contains a terminal frontend for the interpreter, which can also assemble and
+disassemble programs with its asm and disasm subcommands. With the -headless flag, it runs a ROM for a number
+of frames without a terminal and writes out the final display, registers and memory. The -record and -replay
+flags record and replay movies, and -audio plays the sound on the terminal bell or writes it as WAV or raw PCM.
+*/
+package chip8
@@ -0,0 +1,89 @@
+package chip8
+
+import (
+	"fmt"
+)
+
+// FaultKind identifies the kind of a Fault.
+//
+// FaultKind implements error so that a fault can be matched by its kind with errors.Is:
+//
+//	if errors.Is(err, chip8.StackOverflow) {
+//		// ...
+//	}
+type FaultKind int
+
+// Kinds of faults.
+const (
+	// IllegalOpcode is caused by an instruction which the interpreter does not recognise.
+	IllegalOpcode FaultKind = iota + 1
+
+	// StackOverflow is caused by a CALL when all 16 levels of the stack are in use.
+	StackOverflow
+
+	// StackUnderflow is caused by a RET when the stack is empty.
+	StackUnderflow
+
+	// MemoryOutOfBounds is caused by an instruction which reads or writes past the end of memory given by the quirks,
+	// or by the program counter running past it.
+	MemoryOutOfBounds
+)
+
+func (k FaultKind) String() string {
+	switch k {
+	case IllegalOpcode:
+		return "illegal opcode"
+	case StackOverflow:
+		return "stack overflow"
+	case StackUnderflow:
+		return "stack underflow"
+	case MemoryOutOfBounds:
+		return "memory out of bounds"
+	default:
+		return fmt.Sprintf("FaultKind(%d)", int(k))
+	}
+}
+
+func (k FaultKind) Error() string {
+	return "chip8: " + k.String()
+}
+
+// Fault is the error returned when the program executes an instruction that the interpreter cannot carry out.
+//
+// After a fault, the interpreter is stopped and every subsequent call to Step or RunFrame returns the same fault.
+type Fault struct {
+	Kind FaultKind
+
+	// PC is the address of the instruction which caused the fault.
+	PC uint16
+
+	// Instr is the instruction which caused the fault.
+	Instr uint16
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("chip8: %s: instruction 0x%04X at 0x%03X", f.Kind, f.Instr, f.PC)
+}
+
+// Unwrap returns the kind of the fault.
+func (f *Fault) Unwrap() error {
+	return f.Kind
+}
+
+// fault returns a fault of the given kind caused by executing instr at the program counter.
+func (ip *Interpreter) fault(kind FaultKind, instr instruction) error {
+	return &Fault{
+		Kind:  kind,
+		PC:    ip.pc,
+		Instr: instr.word(),
+	}
+}
+
+// checkMemory returns a MemoryOutOfBounds fault caused by instr if the n bytes starting at addr are not all in memory.
+// The size of memory is given by the quirks, so on a 4 KB machine addresses from 0x1000 are out of bounds.
+func (ip *Interpreter) checkMemory(addr uint16, n int, instr instruction) error {
+	if int(addr)+n > ip.quirks.memorySize() {
+		return ip.fault(MemoryOutOfBounds, instr)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package chip8
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// MaxBrightness is the brightness of a pixel which is fully lit.
+const MaxBrightness = 0xFF
+
+// Frame is a display as it appears on screen after filtering, in which each pixel has a colour and a brightness.
+type Frame struct {
+	// Hires is true when the display is in the SUPER-CHIP 128x64 mode.
+	Hires bool
+
+	// Colors holds the colour of each pixel from 0 to 3, indexed by row and then column.
+	Colors [DisplayHeightHires][DisplayWidthHires]uint8
+
+	// Brightness holds how brightly each pixel shows its colour, from 0, where it shows the background,
+	// to MaxBrightness.
+	Brightness [DisplayHeightHires][DisplayWidthHires]uint8
+}
+
+// NewFrame returns the frame which shows d as it is, with every pixel which is set fully lit.
+func NewFrame(d Display) Frame {
+	f := Frame{Hires: d.Hires}
+	for y := 0; y < d.Height(); y++ {
+		for x := 0; x < d.Width(); x++ {
+			if c := d.Pixel(x, y); c > 0 {
+				f.Colors[y][x] = c
+				f.Brightness[y][x] = MaxBrightness
+			}
+		}
+	}
+	return f
+}
+
+// Width returns the width of the frame in pixels.
+func (f *Frame) Width() int {
+	if f.Hires {
+		return DisplayWidthHires
+	}
+	return DisplayWidthLores
+}
+
+// Height returns the height of the frame in pixels.
+func (f *Frame) Height() int {
+	if f.Hires {
+		return DisplayHeightHires
+	}
+	return DisplayHeightLores
+}
+
+// Pixel returns the colour and brightness of the pixel at (x, y).
+// A pixel with a brightness of 0 shows the background, whatever its colour.
+func (f *Frame) Pixel(x, y int) (c, brightness uint8) {
+	if f.Brightness[y][x] == 0 {
+		return 0, 0
+	}
+	return f.Colors[y][x], f.Brightness[y][x]
+}
+
+// String returns the frame as text in the same way as Display.String, drawing every pixel which is lit at all
+// in its colour.
+func (f *Frame) String() string {
+	var b strings.Builder
+	w, h := f.Width(), f.Height()
+	b.Grow((w + 1) * h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c, _ := f.Pixel(x, y)
+			b.WriteByte(displayRunes[c])
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Image returns the frame as an image with one pixel per pixel, using palette for the four colours.
+// Pixels which are not fully lit are blended with the background colour, palette[0].
+func (f *Frame) Image(palette color.Palette) *image.RGBA {
+	w, h := f.Width(), f.Height()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c, brightness := f.Pixel(x, y)
+			img.Set(x, y, Blend(palette[0], palette[c], brightness))
+		}
+	}
+	return img
+}
+
+// Blend returns the colour of a pixel in the colour fg at the given brightness on the background bg.
+func Blend(bg, fg color.Color, brightness uint8) color.RGBA {
+	r0, g0, b0, _ := bg.RGBA()
+	r1, g1, b1, _ := fg.RGBA()
+	mix := func(c0, c1 uint32) uint8 {
+		c := (c0*uint32(MaxBrightness-brightness) + c1*uint32(brightness)) / MaxBrightness
+		return uint8(c >> 8)
+	}
+	return color.RGBA{R: mix(r0, r1), G: mix(g0, g1), B: mix(b0, b1), A: 0xFF}
+}
+
+// A Filter turns each display the interpreter produces into the frame shown on screen.
+// Filters can remember earlier displays, so Filter should be called once for each 60 Hz frame.
+type Filter interface {
+	Filter(d Display) Frame
+}
+
+// NoFilter shows each display as it is.
+type NoFilter struct{}
+
+// Filter returns d as a frame.
+func (NoFilter) Filter(d Display) Frame {
+	return NewFrame(d)
+}
+
+// Persistence simulates the phosphor of a CRT, which keeps glowing after a pixel is turned off, to reduce
+// the flicker of sprites which are erased and redrawn. A pixel which turns off fades out over a number of frames.
+type Persistence struct {
+	frames int
+	last   Frame
+
+	// age holds the number of frames since each pixel was last set.
+	age [DisplayHeightHires][DisplayWidthHires]int
+}
+
+// NewPersistence returns a filter which keeps pixels lit for frames frames after they turn off,
+// getting dimmer each frame.
+func NewPersistence(frames int) *Persistence {
+	return &Persistence{frames: frames}
+}
+
+// Filter returns d with the pixels which were recently turned off fading out.
+func (p *Persistence) Filter(d Display) Frame {
+	f := NewFrame(d)
+	if f.Hires != p.last.Hires {
+		// changing resolution clears the screen
+		p.last = Frame{Hires: f.Hires}
+	}
+	for y := 0; y < f.Height(); y++ {
+		for x := 0; x < f.Width(); x++ {
+			if f.Brightness[y][x] > 0 {
+				p.age[y][x] = 0
+				continue
+			}
+			p.age[y][x]++
+			if c := p.last.Colors[y][x]; c > 0 && p.age[y][x] <= p.frames {
+				f.Colors[y][x] = c
+				f.Brightness[y][x] = uint8(MaxBrightness * (p.frames + 1 - p.age[y][x]) / (p.frames + 1))
+			}
+		}
+	}
+	p.last = f
+	return f
+}
+
+// Merge shows each pixel which is set in either the current or the previous display, to hide sprites which are
+// erased in one frame and redrawn in the next.
+type Merge struct {
+	prev Display
+}
+
+// Filter returns the combination of d and the previous display.
+func (m *Merge) Filter(d Display) Frame {
+	merged := d
+	if d.Hires == m.prev.Hires {
+		for i := range merged.Planes {
+			for y := range merged.Planes[i] {
+				for x := range merged.Planes[i][y] {
+					merged.Planes[i][y][x] |= m.prev.Planes[i][y][x]
+				}
+			}
+		}
+	}
+	m.prev = d
+	return NewFrame(merged)
+}
@@ -0,0 +1,62 @@
+package chip8
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPersistence_Filter(t *testing.T) {
+	var on, off Display
+	on.Planes[1].set(3, 2, true)
+	p := NewPersistence(3)
+	var got []uint8
+	for _, d := range []Display{on, off, off, off, off, on} {
+		f := p.Filter(d)
+		c, brightness := f.Pixel(3, 2)
+		if brightness > 0 && c != 2 {
+			t.Errorf("colour: want = 2, got = %d", c)
+		}
+		got = append(got, brightness)
+	}
+	want := []uint8{MaxBrightness, 0xBF, 0x7F, 0x3F, 0, MaxBrightness}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("brightness mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMerge_Filter(t *testing.T) {
+	var first, second Display
+	first.Planes[0].set(0, 0, true)
+	second.Planes[0].set(1, 0, true)
+	var m Merge
+	m.Filter(first)
+	f := m.Filter(second)
+	for x := 0; x < 2; x++ {
+		if c, _ := f.Pixel(x, 0); c != 1 {
+			t.Errorf("pixel %d: want = 1, got = %d", x, c)
+		}
+	}
+	f = m.Filter(Display{})
+	if c, _ := f.Pixel(0, 0); c != 0 {
+		t.Errorf("pixel 0 after two frames: want = 0, got = %d", c)
+	}
+}
+
+func TestBlend(t *testing.T) {
+	tests := []struct {
+		brightness uint8
+		want       color.RGBA
+	}{
+		{0, color.RGBA{R: 0x00, G: 0x00, B: 0xFF, A: 0xFF}},
+		{MaxBrightness, color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF}},
+		{0x80, color.RGBA{R: 0x80, G: 0x00, B: 0x7F, A: 0xFF}},
+	}
+	for _, tt := range tests {
+		got := Blend(color.RGBA{B: 0xFF, A: 0xFF}, color.RGBA{R: 0xFF, A: 0xFF}, tt.brightness)
+		if got != tt.want {
+			t.Errorf("Blend(%d): want = %v, got = %v", tt.brightness, tt.want, got)
+		}
+	}
+}
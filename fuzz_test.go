@@ -0,0 +1,96 @@
+package chip8
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// fuzzQuirks are the quirks profiles chosen between by the fuzz targets.
+var fuzzQuirks = []Quirks{QuirksCOSMACVIP, QuirksCHIP48, QuirksSUPERCHIP, QuirksXOCHIP}
+
+// checkError fails the test unless err is nil, ErrExited or a *Fault.
+func checkError(t *testing.T, err error) {
+	t.Helper()
+	var fault *Fault
+	if err == nil || err == ErrExited || errors.As(err, &fault) {
+		return
+	}
+	t.Fatalf("undefined error: %v", err)
+}
+
+// FuzzDecode decodes a single instruction and executes it on an interpreter in an arbitrary state.
+func FuzzDecode(f *testing.F) {
+	for _, e := range encodings {
+		f.Add(e.pattern, uint16(0x200), uint8(0), []byte{0x01, 0x02, 0xFF})
+		f.Add(e.pattern|^e.mask, uint16(0xFFFF), uint8(len(Interpreter{}.stack)), []byte{0xFF, 0xFF, 0xFF})
+	}
+	f.Fuzz(func(t *testing.T, word uint16, i uint16, sp uint8, registers []byte) {
+		instr := instruction{hi: uint8(word >> 8), lo: uint8(word)}
+		op := instr.opcode()
+		if op != OpIllegal {
+			if int(op) >= len(encodings) {
+				t.Fatalf("opcode %d of 0x%04X is not in the opcode table", op, word)
+			}
+			if e := encodings[op]; word&e.mask != e.pattern {
+				t.Fatalf("0x%04X decoded as opcode %d, which has the pattern 0x%04X", word, op, e.pattern)
+			}
+		}
+
+		for _, quirks := range fuzzQuirks {
+			ip := New(nil, nil, quirks)
+			if err := ip.Load([]byte{instr.hi, instr.lo}); err != nil {
+				t.Fatal(err)
+			}
+			copy(ip.registers[:], registers)
+			ip.i = i
+			// the stack pointer can be anywhere from empty to full
+			ip.sp = sp % uint8(len(ip.stack)+1)
+			err := ip.Step()
+			checkError(t, err)
+			if op == OpIllegal && !errors.Is(err, IllegalOpcode) {
+				t.Fatalf("0x%04X: want an illegal opcode fault, got %v", word, err)
+			}
+		}
+	})
+}
+
+// FuzzRun loads an arbitrary program and runs it for thousands of instructions.
+func FuzzRun(f *testing.F) {
+	f.Add([]byte{0x12, 0x00}, uint8(0))
+	f.Add([]byte{0x22, 0x00}, uint8(1))
+	f.Add([]byte{0xA0, 0x00, 0xD0, 0x1F, 0x70, 0x01, 0x12, 0x02}, uint8(2))
+	f.Add([]byte{0xF0, 0x00, 0xFF, 0xFF, 0xFF, 0x65}, uint8(3))
+	// the community test ROMs exercise every instruction under each profile
+	roms, err := filepath.Glob(filepath.Join("testdata", "roms", "community", "*.ch8"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range roms {
+		prog, err := ioutil.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for profile := range fuzzQuirks {
+			f.Add(prog, uint8(profile))
+		}
+	}
+	f.Fuzz(func(t *testing.T, prog []byte, profile uint8) {
+		ip := New(nil, nil, fuzzQuirks[int(profile)%len(fuzzQuirks)])
+		if err := ip.Load(prog); err != nil {
+			if !errors.Is(err, ErrProgramTooLarge) {
+				t.Fatalf("undefined error: %v", err)
+			}
+			return
+		}
+		ip.SetInstructionsPerFrame(50)
+		for frame := 0; frame < 100; frame++ {
+			err := ip.RunFrame()
+			checkError(t, err)
+			if err != nil || ip.Exited() {
+				return
+			}
+		}
+	})
+}
@@ -1,8 +1,15 @@
 module github.com/yi-jiayu/chip8
 
-go 1.12
+go 1.18
 
 require (
 	github.com/gdamore/tcell v1.1.1
 	github.com/google/go-cmp v0.3.0
 )
+
+require (
+	github.com/gdamore/encoding v1.0.0 // indirect
+	github.com/lucasb-eyer/go-colorful v0.0.0-20181028223441-12d3b2882a08 // indirect
+	github.com/mattn/go-runewidth v0.0.4 // indirect
+	golang.org/x/text v0.3.0 // indirect
+)
@@ -1,10 +1,14 @@
-package main
+package chip8
 
-//go:generate ./instructions.sh
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
 
-// Opcodes for standard Chip-8 instructions
+// Opcodes for standard Chip-8, SUPER-CHIP and XO-CHIP instructions
 const (
-	OpCLS_00E0 opcode = iota
+	OpCLS_00E0 Opcode = iota
 	OpRET_00EE
 	OpSYS_0nnn
 	OpJP_1nnn
@@ -39,15 +43,167 @@ const (
 	OpLD_Fx33
 	OpLD_Fx55
 	OpLD_Fx65
+
+	// SUPER-CHIP instructions
+	OpSCD_00Cn
+	OpSCR_00FB
+	OpSCL_00FC
+	OpEXIT_00FD
+	OpLOW_00FE
+	OpHIGH_00FF
+	OpLD_Fx30
+	OpLD_Fx75
+	OpLD_Fx85
+
+	// XO-CHIP instructions
+	OpSCU_00Dn
+	OpSAVE_5xy2
+	OpLOAD_5xy3
+	OpLD_F000
+	OpPLANE_Fn01
+	OpAUDIO_F002
+	OpPITCH_Fx3A
+
+	// OpIllegal is the opcode of words which are not valid instructions.
+	OpIllegal Opcode = 0xFF
 )
 
-type opcode uint8
+// Opcode identifies a Chip-8 instruction independently of its operands.
+type Opcode uint8
+
+// encoding describes how an opcode is encoded as a 16-bit word.
+// A word is an instance of the opcode if word&mask == pattern. Some of the remaining bits hold the operands:
+// x and y are set if the opcode has a register in the second or third nibble,
+// and imm is the largest immediate value it can hold in its low bits.
+type encoding struct {
+	pattern uint16
+	mask    uint16
+	x, y    bool
+	imm     uint16
+}
+
+// encodings is the opcode table shared by the decoder and Encode.
+//
+// Implementation note: 5xy0 and 9xy0 are decoded regardless of their last nibble.
+var encodings = [...]encoding{
+	OpCLS_00E0:   {pattern: 0x00E0, mask: 0xFFFF},
+	OpRET_00EE:   {pattern: 0x00EE, mask: 0xFFFF},
+	OpSYS_0nnn:   {pattern: 0x0000, mask: 0xF000, imm: 0xFFF},
+	OpJP_1nnn:    {pattern: 0x1000, mask: 0xF000, imm: 0xFFF},
+	OpCALL_2nnn:  {pattern: 0x2000, mask: 0xF000, imm: 0xFFF},
+	OpSE_3xkk:    {pattern: 0x3000, mask: 0xF000, x: true, imm: 0xFF},
+	OpSNE_4xkk:   {pattern: 0x4000, mask: 0xF000, x: true, imm: 0xFF},
+	OpSE_5xy0:    {pattern: 0x5000, mask: 0xF000, x: true, y: true},
+	OpLD_6xkk:    {pattern: 0x6000, mask: 0xF000, x: true, imm: 0xFF},
+	OpADD_7xkk:   {pattern: 0x7000, mask: 0xF000, x: true, imm: 0xFF},
+	OpLD_8xy0:    {pattern: 0x8000, mask: 0xF00F, x: true, y: true},
+	OpOR_8xy1:    {pattern: 0x8001, mask: 0xF00F, x: true, y: true},
+	OpAND_8xy2:   {pattern: 0x8002, mask: 0xF00F, x: true, y: true},
+	OpXOR_8xy3:   {pattern: 0x8003, mask: 0xF00F, x: true, y: true},
+	OpADD_8xy4:   {pattern: 0x8004, mask: 0xF00F, x: true, y: true},
+	OpSUB_8xy5:   {pattern: 0x8005, mask: 0xF00F, x: true, y: true},
+	OpSHR_8xy6:   {pattern: 0x8006, mask: 0xF00F, x: true, y: true},
+	OpSUBN_8xy7:  {pattern: 0x8007, mask: 0xF00F, x: true, y: true},
+	OpSHL_8xyE:   {pattern: 0x800E, mask: 0xF00F, x: true, y: true},
+	OpSNE_9xy0:   {pattern: 0x9000, mask: 0xF000, x: true, y: true},
+	OpLD_Annn:    {pattern: 0xA000, mask: 0xF000, imm: 0xFFF},
+	OpJP_Bnnn:    {pattern: 0xB000, mask: 0xF000, imm: 0xFFF},
+	OpRND_Cxkk:   {pattern: 0xC000, mask: 0xF000, x: true, imm: 0xFF},
+	OpDRW_Dxyn:   {pattern: 0xD000, mask: 0xF000, x: true, y: true, imm: 0xF},
+	OpSKP_Ex9E:   {pattern: 0xE09E, mask: 0xF0FF, x: true},
+	OpSKNP_ExA1:  {pattern: 0xE0A1, mask: 0xF0FF, x: true},
+	OpLD_Fx07:    {pattern: 0xF007, mask: 0xF0FF, x: true},
+	OpLD_Fx0A:    {pattern: 0xF00A, mask: 0xF0FF, x: true},
+	OpLD_Fx15:    {pattern: 0xF015, mask: 0xF0FF, x: true},
+	OpLD_Fx18:    {pattern: 0xF018, mask: 0xF0FF, x: true},
+	OpADD_Fx1E:   {pattern: 0xF01E, mask: 0xF0FF, x: true},
+	OpLD_Fx29:    {pattern: 0xF029, mask: 0xF0FF, x: true},
+	OpLD_Fx33:    {pattern: 0xF033, mask: 0xF0FF, x: true},
+	OpLD_Fx55:    {pattern: 0xF055, mask: 0xF0FF, x: true},
+	OpLD_Fx65:    {pattern: 0xF065, mask: 0xF0FF, x: true},
+	OpSCD_00Cn:   {pattern: 0x00C0, mask: 0xFFF0, imm: 0xF},
+	OpSCR_00FB:   {pattern: 0x00FB, mask: 0xFFFF},
+	OpSCL_00FC:   {pattern: 0x00FC, mask: 0xFFFF},
+	OpEXIT_00FD:  {pattern: 0x00FD, mask: 0xFFFF},
+	OpLOW_00FE:   {pattern: 0x00FE, mask: 0xFFFF},
+	OpHIGH_00FF:  {pattern: 0x00FF, mask: 0xFFFF},
+	OpLD_Fx30:    {pattern: 0xF030, mask: 0xF0FF, x: true},
+	OpLD_Fx75:    {pattern: 0xF075, mask: 0xF0FF, x: true},
+	OpLD_Fx85:    {pattern: 0xF085, mask: 0xF0FF, x: true},
+	OpSCU_00Dn:   {pattern: 0x00D0, mask: 0xFFF0, imm: 0xF},
+	OpSAVE_5xy2:  {pattern: 0x5002, mask: 0xF00F, x: true, y: true},
+	OpLOAD_5xy3:  {pattern: 0x5003, mask: 0xF00F, x: true, y: true},
+	OpLD_F000:    {pattern: 0xF000, mask: 0xFFFF},
+	OpPLANE_Fn01: {pattern: 0xF001, mask: 0xF0FF, x: true},
+	OpAUDIO_F002: {pattern: 0xF002, mask: 0xFFFF},
+	OpPITCH_Fx3A: {pattern: 0xF03A, mask: 0xF0FF, x: true},
+}
+
+// opcodes maps every 16-bit word to its opcode.
+var opcodes [1 << 16]Opcode
+
+func init() {
+	for i := range opcodes {
+		opcodes[i] = OpIllegal
+	}
+	// fill in the least specific encodings first so that more specific ones, such as 00E0 over 0nnn, take precedence
+	order := make([]Opcode, len(encodings))
+	for i := range order {
+		order[i] = Opcode(i)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return bits.OnesCount16(encodings[order[i]].mask) < bits.OnesCount16(encodings[order[j]].mask)
+	})
+	for _, op := range order {
+		e := encodings[op]
+		free := ^e.mask
+		// visit every combination of the bits outside the mask
+		for b := free; ; b = (b - 1) & free {
+			opcodes[e.pattern|b] = op
+			if b == 0 {
+				break
+			}
+		}
+	}
+}
+
+// Encode returns the instruction word for op with the operands x, y and imm.
+// Operands which op does not have are ignored. The address of LD I, LONG nnnn is the word following the
+// instruction, and is not part of the encoding.
+// Encode returns an error if op is not a valid opcode or an operand does not fit in its field.
+func Encode(op Opcode, x, y uint8, imm uint16) (uint16, error) {
+	if int(op) >= len(encodings) {
+		return 0, fmt.Errorf("chip8: invalid opcode %d", op)
+	}
+	e := encodings[op]
+	w := e.pattern
+	if e.x {
+		if x > 0xF {
+			return 0, fmt.Errorf("chip8: operand 0x%X does not fit in a nibble", x)
+		}
+		w |= uint16(x) << 8
+	}
+	if e.y {
+		if y > 0xF {
+			return 0, fmt.Errorf("chip8: operand 0x%X does not fit in a nibble", y)
+		}
+		w |= uint16(y) << 4
+	}
+	if imm > e.imm {
+		return 0, fmt.Errorf("chip8: operand 0x%X is larger than 0x%X", imm, e.imm)
+	}
+	return w | imm, nil
+}
 
 type instruction struct {
 	hi uint8
 	lo uint8
 }
 
+func (instr instruction) word() uint16 {
+	return uint16(instr.hi)<<8 | uint16(instr.lo)
+}
+
 func (instr instruction) addr() uint16 {
 	return uint16(instr.hi&0x0f)<<8 | uint16(instr.lo)
 }
@@ -68,91 +224,7 @@ func (instr instruction) byte() uint8 {
 	return instr.lo
 }
 
-func (instr instruction) opcode() opcode {
-	op := instr.hi & 0xf0 >> 4
-	switch op {
-	case 0:
-		switch instr.lo {
-		case 0xE0:
-			return OpCLS_00E0
-		case 0xEE:
-			return OpRET_00EE
-		default:
-			return OpSYS_0nnn
-		}
-	case 1:
-		return OpJP_1nnn
-	case 2:
-		return OpCALL_2nnn
-	case 3:
-		return OpSE_3xkk
-	case 4:
-		return OpSNE_4xkk
-	case 5:
-		return OpSE_5xy0
-	case 6:
-		return OpLD_6xkk
-	case 7:
-		return OpADD_7xkk
-	case 8:
-		switch instr.lo & 0xF {
-		case 0:
-			return OpLD_8xy0
-		case 1:
-			return OpOR_8xy1
-		case 2:
-			return OpAND_8xy2
-		case 3:
-			return OpXOR_8xy3
-		case 4:
-			return OpADD_8xy4
-		case 5:
-			return OpSUB_8xy5
-		case 6:
-			return OpSHR_8xy6
-		case 7:
-			return OpSUBN_8xy7
-		case 0xE:
-			return OpSHL_8xyE
-		}
-	case 9:
-		return OpSNE_9xy0
-	case 0xA:
-		return OpLD_Annn
-	case 0xB:
-		return OpJP_Bnnn
-	case 0xC:
-		return OpRND_Cxkk
-	case 0xD:
-		return OpDRW_Dxyn
-	case 0xE:
-		switch instr.lo {
-		case 0x9E:
-			return OpSKP_Ex9E
-		case 0xA1:
-			return OpSKNP_ExA1
-		}
-	case 0xF:
-		switch instr.lo {
-		case 0x07:
-			return OpLD_Fx07
-		case 0x0A:
-			return OpLD_Fx0A
-		case 0x15:
-			return OpLD_Fx15
-		case 0x18:
-			return OpLD_Fx18
-		case 0x1E:
-			return OpADD_Fx1E
-		case 0x29:
-			return OpLD_Fx29
-		case 0x33:
-			return OpLD_Fx33
-		case 0x55:
-			return OpLD_Fx55
-		case 0x65:
-			return OpLD_Fx65
-		}
-	}
-	return 0xFF
+// opcode returns the opcode of instr, or OpIllegal if it is not a valid instruction.
+func (instr instruction) opcode() Opcode {
+	return opcodes[instr.word()]
 }
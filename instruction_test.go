@@ -1,4 +1,4 @@
-package main
+package chip8
 
 import (
 	"testing"
@@ -48,7 +48,7 @@ func Test_instruction_opcode(t *testing.T) {
 	tests := []struct {
 		name        string
 		instruction instruction
-		want        opcode
+		want        Opcode
 	}{
 		{
 			name:        "0nnn SYS addr",
@@ -225,6 +225,91 @@ func Test_instruction_opcode(t *testing.T) {
 			instruction: instruction{0xFa, 0x65},
 			want:        OpLD_Fx65,
 		},
+		{
+			name:        "00Cn SCD nibble",
+			instruction: instruction{0, 0xC4},
+			want:        OpSCD_00Cn,
+		},
+		{
+			name:        "00FB SCR",
+			instruction: instruction{0, 0xFB},
+			want:        OpSCR_00FB,
+		},
+		{
+			name:        "00FC SCL",
+			instruction: instruction{0, 0xFC},
+			want:        OpSCL_00FC,
+		},
+		{
+			name:        "00FD EXIT",
+			instruction: instruction{0, 0xFD},
+			want:        OpEXIT_00FD,
+		},
+		{
+			name:        "00FE LOW",
+			instruction: instruction{0, 0xFE},
+			want:        OpLOW_00FE,
+		},
+		{
+			name:        "00FF HIGH",
+			instruction: instruction{0, 0xFF},
+			want:        OpHIGH_00FF,
+		},
+		{
+			name:        "Dxy0 DRW Vx, Vy, 0",
+			instruction: instruction{0xDa, 0xb0},
+			want:        OpDRW_Dxyn,
+		},
+		{
+			name:        "Fx30 LD HF, Vx",
+			instruction: instruction{0xFa, 0x30},
+			want:        OpLD_Fx30,
+		},
+		{
+			name:        "Fx75 LD R, Vx",
+			instruction: instruction{0xFa, 0x75},
+			want:        OpLD_Fx75,
+		},
+		{
+			name:        "Fx85 LD Vx, R",
+			instruction: instruction{0xFa, 0x85},
+			want:        OpLD_Fx85,
+		},
+		{
+			name:        "00Dn SCU nibble",
+			instruction: instruction{0, 0xD4},
+			want:        OpSCU_00Dn,
+		},
+		{
+			name:        "5xy2 SAVE Vx - Vy",
+			instruction: instruction{0x5a, 0xb2},
+			want:        OpSAVE_5xy2,
+		},
+		{
+			name:        "5xy3 LOAD Vx - Vy",
+			instruction: instruction{0x5a, 0xb3},
+			want:        OpLOAD_5xy3,
+		},
+		{
+			name:        "F000 LD I, long addr",
+			instruction: instruction{0xF0, 0x00},
+			want:        OpLD_F000,
+		},
+		{
+			name:        "Fn01 PLANE n",
+			instruction: instruction{0xF3, 0x01},
+			want:        OpPLANE_Fn01,
+		},
+		{
+			name:        "F002 AUDIO",
+			instruction: instruction{0xF0, 0x02},
+			want:        OpAUDIO_F002,
+		},
+		{
+			name:        "Fx3A PITCH Vx",
+			instruction: instruction{0xFa, 0x3A},
+			want:        OpPITCH_Fx3A,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -234,3 +319,44 @@ func Test_instruction_opcode(t *testing.T) {
 		})
 	}
 }
+
+func TestEncode(t *testing.T) {
+	// every valid instruction word must survive being decoded and encoded again
+	for w := 0; w < 1<<16; w++ {
+		instr := instruction{hi: uint8(w >> 8), lo: uint8(w)}
+		op := instr.opcode()
+		if op == OpIllegal {
+			continue
+		}
+		e := encodings[op]
+		got, err := Encode(op, instr.x(), instr.y(), uint16(w)&e.imm)
+		if err != nil {
+			t.Fatalf("Encode(%d) for 0x%04X: %v", op, w, err)
+		}
+		operands := e.imm
+		if e.x {
+			operands |= 0x0F00
+		}
+		if e.y {
+			operands |= 0x00F0
+		}
+		if want := uint16(w) & (e.mask | operands); got != want {
+			t.Errorf("Encode(%d) = 0x%04X, want 0x%04X", op, got, want)
+		}
+		if decoded := (instruction{hi: uint8(got >> 8), lo: uint8(got)}).opcode(); decoded != op {
+			t.Errorf("0x%04X decoded as %d, want %d", got, decoded, op)
+		}
+	}
+}
+
+func TestEncode_errors(t *testing.T) {
+	if _, err := Encode(OpLD_6xkk, 0x10, 0, 0); err == nil {
+		t.Error("expected error for register out of range")
+	}
+	if _, err := Encode(OpDRW_Dxyn, 0, 0, 0x10); err == nil {
+		t.Error("expected error for nibble out of range")
+	}
+	if _, err := Encode(OpIllegal, 0, 0, 0); err == nil {
+		t.Error("expected error for illegal opcode")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package chip8
 
 import (
 	"math/bits"
@@ -12,14 +12,11 @@ const vF = 0xF
 
 // 00E0 - CLS
 // Clear the display.
-func CLS_00E0(ip *Interpreter, instr instruction) {
-	for i := 0; i < 32; i++ {
-		for j := 0; j < 8; j++ {
-			ip.display[i][j] = 0
-		}
-	}
+func CLS_00E0(ip *Interpreter, instr instruction) error {
+	ip.display.clear(ip.plane)
 	ip.render()
 	ip.pc += instrLen
+	return nil
 }
 
 // 00EE - RET
@@ -28,26 +25,32 @@ func CLS_00E0(ip *Interpreter, instr instruction) {
 // The interpreter sets the program counter to the address at the top of the stack, then subtracts 1 from the stack pointer.
 //
 // Implementation note: we're using a 0-based stack pointer, so we decrement the stack pointer first.
-func RET_00EE(ip *Interpreter, instr instruction) {
+func RET_00EE(ip *Interpreter, instr instruction) error {
+	if ip.sp == 0 {
+		return ip.fault(StackUnderflow, instr)
+	}
 	ip.sp--
 	ip.pc = ip.stack[ip.sp]
 	ip.pc += instrLen
+	return nil
 }
 
 // 0nnn - SYS addr
 // Jump to a machine code routine at nnn.
 //
 // This instruction is only used on the old computers on which Chip-8 was originally implemented. It is ignored by modern interpreters.
-func SYS_0nnn(ip *Interpreter, instr instruction) {
+func SYS_0nnn(ip *Interpreter, instr instruction) error {
 	ip.pc += instrLen
+	return nil
 }
 
 // 1nnn - JP addr
 // Jump to location nnn.
 //
 // The interpreter sets the program counter to nnn.
-func JP_1nnn(ip *Interpreter, instr instruction) {
+func JP_1nnn(ip *Interpreter, instr instruction) error {
 	ip.pc = instr.addr()
+	return nil
 }
 
 // 2nnn - CALL addr
@@ -56,70 +59,80 @@ func JP_1nnn(ip *Interpreter, instr instruction) {
 // The interpreter increments the stack pointer, then puts the current PC on the top of the stack. The PC is then set to nnn.
 //
 // Implementation note: we're using a 0-based stack pointer, so we decrement the stack pointer later.
-func CALL_2nnn(ip *Interpreter, instr instruction) {
+func CALL_2nnn(ip *Interpreter, instr instruction) error {
+	if int(ip.sp) >= len(ip.stack) {
+		return ip.fault(StackOverflow, instr)
+	}
 	ip.stack[ip.sp] = ip.pc
 	ip.sp++
 	ip.pc = instr.addr()
+	return nil
 }
 
 // 3xkk - SE Vx, byte
 // Skip next instruction if Vx = kk.
 //
-// The interpreter compares register Vx to kk, and if they are equal, increments the program counter by 2.
-func SE_3xkk(ip *Interpreter, instr instruction) {
+// The interpreter compares register Vx to kk, and if they are equal, skips the next instruction.
+func SE_3xkk(ip *Interpreter, instr instruction) error {
+	ip.pc += instrLen
 	if ip.registers[instr.x()] == instr.byte() {
-		ip.pc += instrLen
+		ip.skip()
 	}
-	ip.pc += instrLen
+	return nil
 }
 
 // 4xkk - SNE Vx, byte
 // Skip next instruction if Vx != kk.
 //
-// The interpreter compares register Vx to kk, and if they are not equal, increments the program counter by 2.
-func SNE_4xkk(ip *Interpreter, instr instruction) {
+// The interpreter compares register Vx to kk, and if they are not equal, skips the next instruction.
+func SNE_4xkk(ip *Interpreter, instr instruction) error {
+	ip.pc += instrLen
 	if ip.registers[instr.x()] != instr.byte() {
-		ip.pc += instrLen
+		ip.skip()
 	}
-	ip.pc += instrLen
+	return nil
 }
 
 // 5xy0 - SE Vx, Vy
 // Skip next instruction if Vx = Vy.
 //
-// The interpreter compares register Vx to register Vy, and if they are equal, increments the program counter by 2.
-func SE_5xy0(ip *Interpreter, instr instruction) {
+// The interpreter compares register Vx to register Vy, and if they are equal, skips the next instruction.
+func SE_5xy0(ip *Interpreter, instr instruction) error {
+	ip.pc += instrLen
 	if ip.registers[instr.x()] == ip.registers[instr.y()] {
-		ip.pc += instrLen
+		ip.skip()
 	}
-	ip.pc += instrLen
+	return nil
 }
 
 // 6xkk - LD Vx, byte
 // Set Vx = kk.
 //
 // The interpreter puts the value kk into register Vx.
-func LD_6xkk(ip *Interpreter, instr instruction) {
+func LD_6xkk(ip *Interpreter, instr instruction) error {
 	ip.registers[instr.x()] = instr.byte()
 	ip.pc += instrLen
+	return nil
 }
 
 // 7xkk - ADD Vx, byte
 // Set Vx = Vx + kk.
 //
 // Adds the value kk to the value of register Vx, then stores the result in Vx.
-func ADD_7xkk(ip *Interpreter, instr instruction) {
+func ADD_7xkk(ip *Interpreter, instr instruction) error {
 	ip.registers[instr.x()] += instr.byte()
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xy0 - LD Vx, Vy
 // Set Vx = Vy.
 //
 // Stores the value of register Vy in register Vx.
-func LD_8xy0(ip *Interpreter, instr instruction) {
+func LD_8xy0(ip *Interpreter, instr instruction) error {
 	ip.registers[instr.x()] = ip.registers[instr.y()]
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xy1 - OR Vx, Vy
@@ -128,9 +141,15 @@ func LD_8xy0(ip *Interpreter, instr instruction) {
 // Performs a bitwise OR on the values of Vx and Vy, then stores the result in Vx.
 // A bitwise OR compares the corresponding bits from two values, and if either bit is 1,
 // then the same bit in the result is also 1. Otherwise, it is 0.
-func OR_8xy1(ip *Interpreter, instr instruction) {
+//
+// Quirk: With VFReset, VF is set to 0.
+func OR_8xy1(ip *Interpreter, instr instruction) error {
 	ip.registers[instr.x()] |= ip.registers[instr.y()]
+	if ip.quirks.VFReset {
+		ip.registers[vF] = 0
+	}
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xy2 - AND Vx, Vy
@@ -139,9 +158,15 @@ func OR_8xy1(ip *Interpreter, instr instruction) {
 // Performs a bitwise AND on the values of Vx and Vy, then stores the result in Vx.
 // A bitwise AND compares the corresponding bits from two values, and if both bits are 1,
 // then the same bit in the result is also 1. Otherwise, it is 0.
-func AND_8xy2(ip *Interpreter, instr instruction) {
+//
+// Quirk: With VFReset, VF is set to 0.
+func AND_8xy2(ip *Interpreter, instr instruction) error {
 	ip.registers[instr.x()] &= ip.registers[instr.y()]
+	if ip.quirks.VFReset {
+		ip.registers[vF] = 0
+	}
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xy3 - XOR Vx, Vy
@@ -150,9 +175,15 @@ func AND_8xy2(ip *Interpreter, instr instruction) {
 // Performs a bitwise exclusive OR on the values of Vx and Vy, then stores the result in Vx.
 // An exclusive OR compares the corresponding bits from two values, and if the bits are not both the same,
 // then the corresponding bit in the result is set to 1. Otherwise, it is 0.
-func XOR_8xy3(ip *Interpreter, instr instruction) {
+//
+// Quirk: With VFReset, VF is set to 0.
+func XOR_8xy3(ip *Interpreter, instr instruction) error {
 	ip.registers[instr.x()] ^= ip.registers[instr.y()]
+	if ip.quirks.VFReset {
+		ip.registers[vF] = 0
+	}
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xy4 - ADD Vx, Vy
@@ -160,12 +191,13 @@ func XOR_8xy3(ip *Interpreter, instr instruction) {
 //
 // The values of Vx and Vy are added together. If the result is greater than 8 bits (i.e., > 255,) VF is set to 1,
 // otherwise 0. Only the lowest 8 bits of the result are kept, and stored in Vx.
-func ADD_8xy4(ip *Interpreter, instr instruction) {
+func ADD_8xy4(ip *Interpreter, instr instruction) error {
 	x := instr.x()
-	sum, carry := bits.Add(uint(ip.registers[x]), uint(ip.registers[instr.y()]), 0)
+	sum := uint16(ip.registers[x]) + uint16(ip.registers[instr.y()])
 	ip.registers[x] = uint8(sum)
-	ip.registers[vF] = uint8(carry)
+	ip.registers[vF] = uint8(sum >> 8)
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xy5 - SUB Vx, Vy
@@ -174,74 +206,99 @@ func ADD_8xy4(ip *Interpreter, instr instruction) {
 // If Vx > Vy, then VF is set to 1, otherwise 0. Then Vy is subtracted from Vx, and the results stored in Vx.
 //
 // Implementation note: Shouldn't VF be set to 1 when Vx == Vy as well?
-func SUB_8xy5(ip *Interpreter, instr instruction) {
+func SUB_8xy5(ip *Interpreter, instr instruction) error {
 	x := instr.x()
 	y := instr.y()
 	diff, borrow := bits.Sub(uint(ip.registers[x]), uint(ip.registers[y]), 0)
 	ip.registers[x] = uint8(diff)
 	ip.registers[vF] = 1 - uint8(borrow)
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xy6 - SHR Vx {, Vy}
 // Set Vx = Vx SHR 1.
 //
 // If the least-significant bit of Vx is 1, then VF is set to 1, otherwise 0. Then Vx is divided by 2.
-func SHR_8xy6(ip *Interpreter, instr instruction) {
-	ip.registers[vF] = ip.registers[instr.x()] & 1
-	ip.registers[instr.x()] >>= 1
+//
+// Quirk: With ShiftUsesVy, Vy is shifted and the result stored in Vx.
+func SHR_8xy6(ip *Interpreter, instr instruction) error {
+	v := ip.registers[instr.x()]
+	if ip.quirks.ShiftUsesVy {
+		v = ip.registers[instr.y()]
+	}
+	ip.registers[instr.x()] = v >> 1
+	ip.registers[vF] = v & 1
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xy7 - SUBN Vx, Vy
 // Set Vx = Vy - Vx, set VF = NOT borrow.
 //
 // If Vy > Vx, then VF is set to 1, otherwise 0. Then Vx is subtracted from Vy, and the results stored in Vx.
-func SUBN_8xy7(ip *Interpreter, instr instruction) {
+func SUBN_8xy7(ip *Interpreter, instr instruction) error {
 	x := instr.x()
 	y := instr.y()
 	diff, borrow := bits.Sub(uint(ip.registers[y]), uint(ip.registers[x]), 0)
 	ip.registers[x] = uint8(diff)
 	ip.registers[vF] = 1 - uint8(borrow)
 	ip.pc += instrLen
+	return nil
 }
 
 // 8xyE - SHL Vx {, Vy}
 // Set Vx = Vx SHL 1.
 //
 // If the most-significant bit of Vx is 1, then VF is set to 1, otherwise to 0. Then Vx is multiplied by 2.
-func SHL_8xyE(ip *Interpreter, instr instruction) {
-	ip.registers[vF] = ip.registers[instr.x()] >> 7 & 1
-	ip.registers[instr.x()] <<= 1
+//
+// Quirk: With ShiftUsesVy, Vy is shifted and the result stored in Vx.
+func SHL_8xyE(ip *Interpreter, instr instruction) error {
+	v := ip.registers[instr.x()]
+	if ip.quirks.ShiftUsesVy {
+		v = ip.registers[instr.y()]
+	}
+	ip.registers[instr.x()] = v << 1
+	ip.registers[vF] = v >> 7 & 1
 	ip.pc += instrLen
+	return nil
 }
 
 // 9xy0 - SNE Vx, Vy
 // Skip next instruction if Vx != Vy.
 //
-// The values of Vx and Vy are compared, and if they are not equal, the program counter is increased by 2.
-func SNE_9xy0(ip *Interpreter, instr instruction) {
+// The values of Vx and Vy are compared, and if they are not equal, the next instruction is skipped.
+func SNE_9xy0(ip *Interpreter, instr instruction) error {
+	ip.pc += instrLen
 	if ip.registers[instr.x()] != ip.registers[instr.y()] {
-		ip.pc += instrLen
+		ip.skip()
 	}
-	ip.pc += instrLen
+	return nil
 }
 
 // Annn - LD I, addr
 // Set I = nnn.
 //
 // The value of register I is set to nnn.
-func LD_Annn(ip *Interpreter, instr instruction) {
+func LD_Annn(ip *Interpreter, instr instruction) error {
 	ip.i = instr.addr()
 	ip.pc += instrLen
+	return nil
 }
 
 // Bnnn - JP V0, addr
 // Jump to location nnn + V0.
 //
 // The program counter is set to nnn plus the value of V0.
-func JP_Bnnn(ip *Interpreter, instr instruction) {
+//
+// Quirk: With JumpUsesVx, the instruction is treated as Bxnn and the program counter is set to xnn plus the value of Vx.
+func JP_Bnnn(ip *Interpreter, instr instruction) error {
+	if ip.quirks.JumpUsesVx {
+		ip.pc = instr.addr() + uint16(ip.registers[instr.x()])
+		return nil
+	}
 	ip.pc = instr.addr() + uint16(ip.registers[0])
+	return nil
 }
 
 // Cxkk - RND Vx, byte
@@ -249,10 +306,11 @@ func JP_Bnnn(ip *Interpreter, instr instruction) {
 //
 // The interpreter generates a random number from 0 to 255, which is then ANDed with the value kk.
 // The results are stored in Vx. See instruction 8xy2 for more information on AND.
-func RND_Cxkk(ip *Interpreter, instr instruction) {
+func RND_Cxkk(ip *Interpreter, instr instruction) error {
 	r := ip.rand()
 	ip.registers[instr.x()] = r & instr.byte()
 	ip.pc += instrLen
+	return nil
 }
 
 // Dxyn - DRW Vx, Vy, nibble
@@ -266,110 +324,159 @@ func RND_Cxkk(ip *Interpreter, instr instruction) {
 // around to the opposite side of the screen. See instruction 8xy3 for more
 // information on XOR, and section 2.4, Display, for more information on the
 // Chip-8 screen and sprites.
-func DRW_Dxyn(ip *Interpreter, instr instruction) {
+//
+// SUPER-CHIP: If n is 0, a 16x16 sprite of 32 bytes is drawn instead.
+//
+// XO-CHIP: The sprite is drawn to each selected plane in turn.
+//
+// Quirk: With ClipSprites, the parts of a sprite outside the display are not drawn.
+// With DisplayWait, execution pauses until the next display refresh after drawing.
+func DRW_Dxyn(ip *Interpreter, instr instruction) error {
 	n := instr.nibble()
-	x := ip.registers[instr.x()]
-	y := ip.registers[instr.y()]
-	sprite := ip.memory[ip.i : ip.i+uint16(n)]
-	var collision uint8
-	for i := uint8(0); i < n; i++ {
-		rowIdx := (y + i) & 0x1F
-		colGrpIdx := (x >> 3) & 0x7
-		rem := x & 0x7
-		if rem > 0 {
-			collision |= ip.display[rowIdx][colGrpIdx] & (sprite[i] >> rem)
-			ip.display[rowIdx][colGrpIdx] ^= sprite[i] >> rem
-			collision |= ip.display[rowIdx][(colGrpIdx+1)&0x7] & (sprite[i] << (8 - rem))
-			ip.display[rowIdx][(colGrpIdx+1)&0x7] ^= sprite[i] << (8 - rem)
-		} else {
-			collision |= ip.display[rowIdx][colGrpIdx] & sprite[i]
-			ip.display[rowIdx][colGrpIdx] ^= sprite[i]
+	width, height := 8, int(n)
+	if n == 0 {
+		// SUPER-CHIP: Dxy0 draws a 16x16 sprite stored as 2 bytes per row.
+		width, height = 16, 16
+	}
+	rowLen := width / 8
+	spriteLen := height * rowLen
+	w, h := ip.display.Width(), ip.display.Height()
+	x := int(ip.registers[instr.x()]) % w
+	y := int(ip.registers[instr.y()]) % h
+	planes := bits.OnesCount8(ip.plane)
+	if err := ip.checkMemory(ip.i, planes*spriteLen, instr); err != nil {
+		return err
+	}
+	addr := int(ip.i)
+	var collision bool
+	for p := range ip.display.Planes {
+		if ip.plane&(1<<uint(p)) == 0 {
+			continue
 		}
+		plane := &ip.display.Planes[p]
+		sprite := ip.memory[addr : addr+spriteLen]
+		for row := 0; row < height; row++ {
+			for col := 0; col < width; col++ {
+				if sprite[row*rowLen+col>>3]&(0x80>>uint(col&7)) == 0 {
+					continue
+				}
+				if ip.quirks.ClipSprites && (x+col >= w || y+row >= h) {
+					continue
+				}
+				if plane.flip((x+col)%w, (y+row)%h) {
+					collision = true
+				}
+			}
+		}
+		// XO-CHIP: when more than one plane is selected, each plane is drawn with the next sprite in memory.
+		addr += spriteLen
 	}
-	if collision > 0 {
+	if collision {
 		ip.registers[vF] = 1
 	} else {
 		ip.registers[vF] = 0
 	}
 	ip.render()
+	if ip.quirks.DisplayWait {
+		ip.vblankWait = true
+	}
 	ip.pc += instrLen
+	return nil
 }
 
 // Ex9E - SKP Vx
 // Skip next instruction if key with the value of Vx is pressed.
 //
-// Checks the keyboard, and if the key corresponding to the value of Vx is currently in the down position, PC is increased by 2.
-func SKP_Ex9E(ip *Interpreter, instr instruction) {
+// Checks the keyboard, and if the key corresponding to the value of Vx is currently in the down position, the next instruction is skipped.
+func SKP_Ex9E(ip *Interpreter, instr instruction) error {
 	key := ip.registers[instr.x()]
 	var mask uint16 = 1 << key
-	keypad := <-ip.keypadch
+	keypad := ip.keys()
+	ip.pc += instrLen
 	if keypad&mask > 0 {
-		ip.pc += instrLen
+		ip.skip()
 	}
-	ip.pc += instrLen
+	return nil
 }
 
 // ExA1 - SKNP Vx
 // Skip next instruction if key with the value of Vx is not pressed.
 //
-// Checks the keyboard, and if the key corresponding to the value of Vx is currently in the up position, PC is increased by 2.
-func SKNP_ExA1(ip *Interpreter, instr instruction) {
+// Checks the keyboard, and if the key corresponding to the value of Vx is currently in the up position, the next instruction is skipped.
+func SKNP_ExA1(ip *Interpreter, instr instruction) error {
 	key := ip.registers[instr.x()]
 	var mask uint16 = 1 << key
-	keypad := <-ip.keypadch
+	keypad := ip.keys()
+	ip.pc += instrLen
 	if keypad&mask == 0 {
-		ip.pc += instrLen
+		ip.skip()
 	}
-	ip.pc += instrLen
+	return nil
 }
 
 // Fx07 - LD Vx, DT
 // Set Vx = delay timer value.
 //
 // The value of DT is placed into Vx.
-func LD_Fx07(ip *Interpreter, instr instruction) {
-	ip.registers[instr.x()] = <-ip.dtget
+func LD_Fx07(ip *Interpreter, instr instruction) error {
+	ip.registers[instr.x()] = ip.dt
 	ip.pc += instrLen
+	return nil
 }
 
 // Fx0A - LD Vx, K
 // Wait for a key press, store the value of the key in Vx.
 //
 // All execution stops until a key is pressed, then the value of that key is stored in Vx.
-func LD_Fx0A(ip *Interpreter, instr instruction) {
-	keypad := <-ip.keypadch
-	zeros := bits.LeadingZeros16(keypad)
-	if zeros < 16 {
-		ip.registers[instr.x()] = uint8(0xF - zeros)
-		ip.pc += instrLen
+//
+// Implementation note: As on the COSMAC VIP, the key is stored once it is released, so that a key held down is not
+// read again by the next Fx0A.
+func LD_Fx0A(ip *Interpreter, instr instruction) error {
+	keypad := ip.keys()
+	if ip.keyWait {
+		if keypad&(1<<ip.waitKey) == 0 {
+			ip.registers[instr.x()] = ip.waitKey
+			ip.keyWait = false
+			ip.pc += instrLen
+		}
+		return nil
 	}
+	if zeros := bits.LeadingZeros16(keypad); zeros < 16 {
+		ip.keyWait = true
+		ip.waitKey = uint8(0xF - zeros)
+	}
+	return nil
 }
 
 // Fx15 - LD DT, Vx
 // Set delay timer = Vx.
 //
 // DT is set equal to the value of Vx.
-func LD_Fx15(ip *Interpreter, instr instruction) {
-	ip.dtset <- ip.registers[instr.x()]
+func LD_Fx15(ip *Interpreter, instr instruction) error {
+	ip.dt = ip.registers[instr.x()]
 	ip.pc += instrLen
+	return nil
 }
 
 // Fx18 - LD ST, Vx
 // Set sound timer = Vx.
 //
 // ST is set equal to the value of Vx.
-func LD_Fx18(ip *Interpreter, instr instruction) {
-	ip.stset <- ip.registers[instr.x()]
+func LD_Fx18(ip *Interpreter, instr instruction) error {
+	ip.st = ip.registers[instr.x()]
+	ip.updateSound()
 	ip.pc += instrLen
+	return nil
 }
 
 // Fx1E - ADD I, Vx
 // Set I = I + Vx.
 //
 // The values of I and Vx are added, and the results are stored in I.
-func ADD_Fx1E(ip *Interpreter, instr instruction) {
+func ADD_Fx1E(ip *Interpreter, instr instruction) error {
 	ip.i += uint16(ip.registers[instr.x()])
 	ip.pc += instrLen
+	return nil
 }
 
 // Fx29 - LD F, Vx
@@ -379,9 +486,10 @@ func ADD_Fx1E(ip *Interpreter, instr instruction) {
 // See section 2.4, Display, for more information on the Chip-8 hexadecimal font.
 //
 // Implementation note: The sprite for digit x is loaded at address x << 3.
-func LD_Fx29(ip *Interpreter, instr instruction) {
+func LD_Fx29(ip *Interpreter, instr instruction) error {
 	ip.i = uint16(ip.registers[instr.x()] << 3)
 	ip.pc += instrLen
+	return nil
 }
 
 // Fx33 - LD B, Vx
@@ -389,33 +497,244 @@ func LD_Fx29(ip *Interpreter, instr instruction) {
 //
 // The interpreter takes the decimal value of Vx, and places the hundreds digit in memory at location in I,
 // the tens digit at location I+1, and the ones digit at location I+2.
-func LD_Fx33(ip *Interpreter, instr instruction) {
+func LD_Fx33(ip *Interpreter, instr instruction) error {
 	x := ip.registers[instr.x()]
 	ones := x % 10
 	tens := (x / 10) % 10
 	hundreds := x / 100
+	if err := ip.checkMemory(ip.i, 3, instr); err != nil {
+		return err
+	}
 	ip.memory[ip.i] = hundreds
 	ip.memory[ip.i+1] = tens
 	ip.memory[ip.i+2] = ones
 	ip.pc += instrLen
+	return nil
 }
 
 // Fx55 - LD [I], Vx
 // Store registers V0 through Vx in memory starting at location I.
 //
 // The interpreter copies the values of registers V0 through Vx into memory, starting at the address in I.
-func LD_Fx55(ip *Interpreter, instr instruction) {
+//
+// Quirk: With LoadStoreIncrementsI, I is set to I + x + 1.
+func LD_Fx55(ip *Interpreter, instr instruction) error {
+	if err := ip.checkMemory(ip.i, int(instr.x())+1, instr); err != nil {
+		return err
+	}
 	copy(ip.memory[ip.i:], ip.registers[:instr.x()+1])
-	ip.i += uint16(instr.x()) + 1
+	if ip.quirks.LoadStoreIncrementsI {
+		ip.i += uint16(instr.x()) + 1
+	}
 	ip.pc += instrLen
+	return nil
 }
 
 // Fx65 - LD Vx, [I]
 // Read registers V0 through Vx from memory starting at location I.
 //
 // The interpreter reads values from memory starting at location I into registers V0 through Vx.
-func LD_Fx65(ip *Interpreter, instr instruction) {
+//
+// Quirk: With LoadStoreIncrementsI, I is set to I + x + 1.
+func LD_Fx65(ip *Interpreter, instr instruction) error {
+	if err := ip.checkMemory(ip.i, int(instr.x())+1, instr); err != nil {
+		return err
+	}
 	copy(ip.registers[:instr.x()+1], ip.memory[ip.i:])
-	ip.i += uint16(instr.x()) + 1
+	if ip.quirks.LoadStoreIncrementsI {
+		ip.i += uint16(instr.x()) + 1
+	}
+	ip.pc += instrLen
+	return nil
+}
+
+// 00Cn - SCD nibble
+// Scroll display n lines down.
+//
+// SUPER-CHIP: The contents of the display are moved down by n rows of the current resolution.
+func SCD_00Cn(ip *Interpreter, instr instruction) error {
+	ip.display.scrollDown(ip.plane, int(instr.nibble()))
+	ip.render()
+	ip.pc += instrLen
+	return nil
+}
+
+// 00FB - SCR
+// Scroll display 4 pixels right.
+func SCR_00FB(ip *Interpreter, instr instruction) error {
+	ip.display.scrollRight(ip.plane, 4)
+	ip.render()
+	ip.pc += instrLen
+	return nil
+}
+
+// 00FC - SCL
+// Scroll display 4 pixels left.
+func SCL_00FC(ip *Interpreter, instr instruction) error {
+	ip.display.scrollLeft(ip.plane, 4)
+	ip.render()
+	ip.pc += instrLen
+	return nil
+}
+
+// 00FD - EXIT
+// Exit the interpreter.
+func EXIT_00FD(ip *Interpreter, instr instruction) error {
+	ip.exited = true
+	return nil
+}
+
+// 00FE - LOW
+// Disable extended screen mode.
+//
+// Implementation note: switching resolution also clears the display.
+func LOW_00FE(ip *Interpreter, instr instruction) error {
+	ip.display.Hires = false
+	ip.display.clear(allPlanes)
+	ip.render()
+	ip.pc += instrLen
+	return nil
+}
+
+// 00FF - HIGH
+// Enable extended screen mode for full-screen graphics.
+//
+// Implementation note: switching resolution also clears the display.
+func HIGH_00FF(ip *Interpreter, instr instruction) error {
+	ip.display.Hires = true
+	ip.display.clear(allPlanes)
+	ip.render()
+	ip.pc += instrLen
+	return nil
+}
+
+// Fx30 - LD HF, Vx
+// Set I = location of 10-byte sprite for digit Vx.
+//
+// Implementation note: The big sprite for digit x is loaded at address memoryOffsetBigSprites + x * 10.
+func LD_Fx30(ip *Interpreter, instr instruction) error {
+	ip.i = memoryOffsetBigSprites + uint16(ip.registers[instr.x()]&0xF)*bigSpriteLen
+	ip.pc += instrLen
+	return nil
+}
+
+// Fx75 - LD R, Vx
+// Store V0 through Vx in RPL user flags.
+func LD_Fx75(ip *Interpreter, instr instruction) error {
+	copy(ip.flags[:], ip.registers[:instr.x()+1])
+	ip.pc += instrLen
+	return nil
+}
+
+// Fx85 - LD Vx, R
+// Read V0 through Vx from RPL user flags.
+func LD_Fx85(ip *Interpreter, instr instruction) error {
+	copy(ip.registers[:instr.x()+1], ip.flags[:])
+	ip.pc += instrLen
+	return nil
+}
+
+// 00Dn - SCU nibble
+// Scroll display n lines up.
+//
+// XO-CHIP: The contents of the selected planes are moved up by n rows of the current resolution.
+func SCU_00Dn(ip *Interpreter, instr instruction) error {
+	ip.display.scrollUp(ip.plane, int(instr.nibble()))
+	ip.render()
+	ip.pc += instrLen
+	return nil
+}
+
+// 5xy2 - SAVE Vx - Vy
+// Store registers Vx through Vy in memory starting at location I.
+//
+// XO-CHIP: If x is greater than y, the registers are stored in reverse order. I is not modified.
+func SAVE_5xy2(ip *Interpreter, instr instruction) error {
+	x, y := int(instr.x()), int(instr.y())
+	step := 1
+	if x > y {
+		step = -1
+	}
+	if err := ip.checkMemory(ip.i, (y-x)*step+1, instr); err != nil {
+		return err
+	}
+	for i, r := 0, x; ; i, r = i+1, r+step {
+		ip.memory[ip.i+uint16(i)] = ip.registers[r]
+		if r == y {
+			break
+		}
+	}
+	ip.pc += instrLen
+	return nil
+}
+
+// 5xy3 - LOAD Vx - Vy
+// Read registers Vx through Vy from memory starting at location I.
+//
+// XO-CHIP: If x is greater than y, the registers are loaded in reverse order. I is not modified.
+func LOAD_5xy3(ip *Interpreter, instr instruction) error {
+	x, y := int(instr.x()), int(instr.y())
+	step := 1
+	if x > y {
+		step = -1
+	}
+	if err := ip.checkMemory(ip.i, (y-x)*step+1, instr); err != nil {
+		return err
+	}
+	for i, r := 0, x; ; i, r = i+1, r+step {
+		ip.registers[r] = ip.memory[ip.i+uint16(i)]
+		if r == y {
+			break
+		}
+	}
+	ip.pc += instrLen
+	return nil
+}
+
+// F000 nnnn - LD I, long addr
+// Set I = nnnn.
+//
+// XO-CHIP: The 16-bit address is stored in the two bytes following the instruction,
+// which makes this the only instruction that is 4 bytes long.
+func LD_F000(ip *Interpreter, instr instruction) error {
+	if err := ip.checkMemory(ip.pc, 2*instrLen, instr); err != nil {
+		return err
+	}
+	ip.i = uint16(ip.memory[ip.pc+2])<<8 | uint16(ip.memory[ip.pc+3])
+	ip.pc += 2 * instrLen
+	return nil
+}
+
+// Fn01 - PLANE n
+// Select drawing planes n.
+//
+// XO-CHIP: Bit 0 of n selects the first plane and bit 1 selects the second plane.
+// CLS, DRW and the scroll instructions only affect the selected planes.
+func PLANE_Fn01(ip *Interpreter, instr instruction) error {
+	ip.plane = instr.x() & allPlanes
+	ip.pc += instrLen
+	return nil
+}
+
+// F002 - AUDIO
+// Load the 16-byte audio pattern buffer from memory starting at location I.
+func AUDIO_F002(ip *Interpreter, instr instruction) error {
+	if err := ip.checkMemory(ip.i, len(ip.pattern), instr); err != nil {
+		return err
+	}
+	copy(ip.pattern[:], ip.memory[ip.i:])
+	ip.updateSound()
+	ip.pc += instrLen
+	return nil
+}
+
+// Fx3A - PITCH Vx
+// Set the audio pattern playback rate to Vx.
+//
+// XO-CHIP: The pattern is played back at 4000*2^((Vx-64)/48) bits per second.
+func PITCH_Fx3A(ip *Interpreter, instr instruction) error {
+	ip.pitch = ip.registers[instr.x()]
+	ip.updateSound()
 	ip.pc += instrLen
+	return nil
 }
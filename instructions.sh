@@ -1,5 +1,8 @@
 #!/bin/sh -
 
+# instructions.sh writes an empty handler for each instruction to instructions.go, replacing the implementations.
+# It was used to start instructions.go and is not run by go generate.
+
 ops='CLS_00E0
 RET_00EE
 SYS_0nnn
@@ -34,14 +37,37 @@ ADD_Fx1E
 LD_Fx29
 LD_Fx33
 LD_Fx55
-LD_Fx65'
+LD_Fx65
+SCD_00Cn
+SCR_00FB
+SCL_00FC
+EXIT_00FD
+LOW_00FE
+HIGH_00FF
+LD_Fx30
+LD_Fx75
+LD_Fx85
+SCU_00Dn
+SAVE_5xy2
+LOAD_5xy3
+LD_F000
+PLANE_Fn01
+AUDIO_F002
+PITCH_Fx3A'
+
+echo "package chip8
+
+// instrLen is the length of a single instruction in bytes.
+const instrLen = 2
 
-echo "package main
+// vF is the address of register VF
+const vF = 0xF
 " > instructions.go
 
 for op in $ops; do cat <<EOF
-func $op(ip *Interpreter, instr instruction) {
+func $op(ip *Interpreter, instr instruction) error {
 
+	return nil
 }
 
 EOF
@@ -1,4 +1,4 @@
-package main
+package chip8
 
 import (
 	"testing"
@@ -16,11 +16,11 @@ func (regs registers) set(idx int, val uint8) registers {
 }
 
 type display struct {
-	d [32][8]uint8
+	d Display
 }
 
-func (d display) set(idx int, val [8]uint8) display {
-	d.d[idx] = val
+func (d display) set(idx int, val [DisplayWidthHires / 8]uint8) display {
+	d.d.Planes[0][idx] = val
 	return d
 }
 
@@ -45,6 +45,89 @@ func newInstructionXKk(x, kk uint8) instruction {
 	}
 }
 
+func TestADD_8xy4(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "no carry",
+			ip: Interpreter{
+				registers: [16]uint8{0x10, 0x20},
+			},
+			instr: newInstructionXYN(0, 1, 4),
+			expected: Interpreter{
+				registers: [16]uint8{0x30, 0x20},
+				pc:        instrLen,
+			},
+		},
+		{
+			name: "carry",
+			ip: Interpreter{
+				registers: [16]uint8{0xF0, 0x20},
+			},
+			instr: newInstructionXYN(0, 1, 4),
+			expected: Interpreter{
+				registers: registers{[16]uint8{0x10, 0x20}}.set(vF, 1).r,
+				pc:        instrLen,
+			},
+		},
+		{
+			name: "Vx is VF",
+			ip: Interpreter{
+				registers: registers{[16]uint8{1: 0x20}}.set(vF, 0xF0).r,
+			},
+			instr: newInstructionXYN(vF, 1, 4),
+			expected: Interpreter{
+				registers: registers{[16]uint8{1: 0x20}}.set(vF, 1).r,
+				pc:        instrLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ADD_8xy4(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+// keys is a Keypad with a fixed set of keys held down.
+type keys uint16
+
+func (k keys) Keys() uint16 {
+	return uint16(k)
+}
+
+func TestLD_Fx0A(t *testing.T) {
+	ip := New(nil, nil, QuirksCOSMACVIP)
+	ip.Load([]byte{0xF3, 0x0A}) // LD V3, K
+	for _, tt := range []struct {
+		keys   keys
+		wantPC uint16
+	}{
+		{0, memoryOffsetProgram},
+		{1 << 5, memoryOffsetProgram},
+		{1<<5 | 1<<2, memoryOffsetProgram},
+		{1 << 2, memoryOffsetProgram + instrLen},
+	} {
+		ip.keypad = tt.keys
+		if err := ip.Step(); err != nil {
+			t.Fatal(err)
+		}
+		if ip.pc != tt.wantPC {
+			t.Fatalf("keys %016b: PC: want = 0x%X, got = 0x%X", tt.keys, tt.wantPC, ip.pc)
+		}
+	}
+	if got := ip.registers[3]; got != 5 {
+		t.Errorf("V3: want = 5, got = %d", got)
+	}
+}
+
 func TestSUB_8xy5(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -125,6 +208,19 @@ func TestSHL_8xyE(t *testing.T) {
 				pc:        instrLen,
 			},
 		},
+		{
+			name: "ShiftUsesVy",
+			ip: Interpreter{
+				registers: [16]uint8{0x01, 0x81},
+				quirks:    Quirks{ShiftUsesVy: true},
+			},
+			instr: newInstructionXYN(0, 1, 0xE),
+			expected: Interpreter{
+				registers: registers{[16]uint8{0x02, 0x81}}.set(vF, 1).r,
+				quirks:    Quirks{ShiftUsesVy: true},
+				pc:        instrLen,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -146,8 +242,9 @@ func TestDRW_Dxyn(t *testing.T) {
 		{
 			name: "3-byte sprite, aligned, no wrap, no collision",
 			ip: Interpreter{
+				plane: 1,
 				// sprite for "0"
-				memory: [4096]uint8{
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -157,26 +254,29 @@ func TestDRW_Dxyn(t *testing.T) {
 			},
 			instr: newInstructionXYN(0, 1, 3),
 			expected: Interpreter{
-				memory: [4096]uint8{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
 					0x90,
 					0xF0,
 				},
-				display: [32][8]uint8{
+				display: Display{Planes: [2]Plane{{
 					{0xF0},
 					{0x90},
 					{0x90},
-				},
+				}}},
 				pc: 2,
 			},
 		},
 		{
 			name: "5-byte sprite, aligned, no wrap, no collision",
 			ip: Interpreter{
+				plane: 1,
 				// sprite for "0"
-				memory: [4096]uint8{
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -186,41 +286,46 @@ func TestDRW_Dxyn(t *testing.T) {
 			},
 			instr: newInstructionXYN(0, 1, 5),
 			expected: Interpreter{
-				memory: [4096]uint8{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
 					0x90,
 					0xF0,
 				},
-				display: [32][8]uint8{
+				display: Display{Planes: [2]Plane{{
 					{0xF0},
 					{0x90},
 					{0x90},
 					{0x90},
 					{0xF0},
-				},
+				}}},
 				pc: 2,
 			},
 		},
 		{
 			name: "5-byte sprite, aligned, no wrap, collision",
 			ip: Interpreter{
+				plane: 1,
 				// sprite for "0"
-				memory: [4096]uint8{
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
 					0x90,
 					0xF0,
 				},
-				display: [32][8]uint8{
+				display: Display{Planes: [2]Plane{{
 					{0xFF},
-				},
+				}}},
 			},
 			instr: newInstructionXYN(0, 1, 5),
 			expected: Interpreter{
-				memory: [4096]uint8{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -228,21 +333,22 @@ func TestDRW_Dxyn(t *testing.T) {
 					0xF0,
 				},
 				registers: registers{}.set(vF, 1).r,
-				display: [32][8]uint8{
+				display: Display{Planes: [2]Plane{{
 					{0x0F},
 					{0x90},
 					{0x90},
 					{0x90},
 					{0xF0},
-				},
+				}}},
 				pc: 2,
 			},
 		},
 		{
 			name: "5-byte sprite, aligned, wraps vertically, no collision",
 			ip: Interpreter{
+				plane: 1,
 				// sprite for "0"
-				memory: [4096]uint8{
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -253,7 +359,9 @@ func TestDRW_Dxyn(t *testing.T) {
 			},
 			instr: newInstructionXYN(0, 1, 5),
 			expected: Interpreter{
-				memory: [4096]uint8{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -261,21 +369,22 @@ func TestDRW_Dxyn(t *testing.T) {
 					0xF0,
 				},
 				registers: [16]uint8{0, 30},
-				display: display{[32][8]uint8{
+				display: display{Display{Planes: [2]Plane{{
 					{0x90},
 					{0x90},
 					{0xF0},
-				}}.
-					set(30, [8]uint8{0xF0}).
-					set(31, [8]uint8{0x90}).d,
+				}}}}.
+					set(30, [16]uint8{0xF0}).
+					set(31, [16]uint8{0x90}).d,
 				pc: 2,
 			},
 		},
 		{
 			name: "5-byte sprite, not aligned, no wrap, no collision",
 			ip: Interpreter{
+				plane: 1,
 				// sprite for "0"
-				memory: [4096]uint8{
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -286,7 +395,9 @@ func TestDRW_Dxyn(t *testing.T) {
 			},
 			instr: newInstructionXYN(0, 1, 5),
 			expected: Interpreter{
-				memory: [4096]uint8{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -294,21 +405,22 @@ func TestDRW_Dxyn(t *testing.T) {
 					0xF0,
 				},
 				registers: [16]uint8{6},
-				display: [32][8]uint8{
+				display: Display{Planes: [2]Plane{{
 					{0x3, 0xC0},
 					{0x2, 0x40},
 					{0x2, 0x40},
 					{0x2, 0x40},
 					{0x3, 0xC0},
-				},
+				}}},
 				pc: 2,
 			},
 		},
 		{
 			name: "5-byte sprite, not aligned, no wrap, collision",
 			ip: Interpreter{
+				plane: 1,
 				// sprite for "0"
-				memory: [4096]uint8{
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -316,13 +428,15 @@ func TestDRW_Dxyn(t *testing.T) {
 					0xF0,
 				},
 				registers: [16]uint8{6},
-				display: [32][8]uint8{
+				display: Display{Planes: [2]Plane{{
 					{0xFF},
-				},
+				}}},
 			},
 			instr: newInstructionXYN(0, 1, 5),
 			expected: Interpreter{
-				memory: [4096]uint8{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -330,21 +444,22 @@ func TestDRW_Dxyn(t *testing.T) {
 					0xF0,
 				},
 				registers: registers{r: [16]uint8{6}}.set(vF, 1).r,
-				display: [32][8]uint8{
+				display: Display{Planes: [2]Plane{{
 					{0xFC, 0xC0},
 					{0x2, 0x40},
 					{0x2, 0x40},
 					{0x2, 0x40},
 					{0x3, 0xC0},
-				},
+				}}},
 				pc: 2,
 			},
 		},
 		{
 			name: "5-byte sprite, not aligned, wraps horizontally, no collision",
 			ip: Interpreter{
+				plane: 1,
 				// sprite for "0"
-				memory: [4096]uint8{
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -355,7 +470,9 @@ func TestDRW_Dxyn(t *testing.T) {
 			},
 			instr: newInstructionXYN(0, 1, 5),
 			expected: Interpreter{
-				memory: [4096]uint8{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
 					0xF0,
 					0x90,
 					0x90,
@@ -363,16 +480,172 @@ func TestDRW_Dxyn(t *testing.T) {
 					0xF0,
 				},
 				registers: [16]uint8{62},
-				display: [32][8]uint8{
+				display: Display{Planes: [2]Plane{{
 					{0xC0, 00, 00, 00, 00, 00, 00, 0x3},
 					{0x40, 00, 00, 00, 00, 00, 00, 0x2},
 					{0x40, 00, 00, 00, 00, 00, 00, 0x2},
 					{0x40, 00, 00, 00, 00, 00, 00, 0x2},
 					{0xC0, 00, 00, 00, 00, 00, 00, 0x3},
+				}}},
+				pc: 2,
+			},
+		},
+		{
+			name: "5-byte sprite, hires, wraps horizontally, no collision",
+			ip: Interpreter{
+				plane: 1,
+				// sprite for "0"
+				memory: [65536]uint8{
+					0xF0,
+					0x90,
+					0x90,
+					0x90,
+					0xF0,
+				},
+				registers: [16]uint8{126},
+				display:   Display{Hires: true},
+			},
+			instr: newInstructionXYN(0, 1, 5),
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
+					0xF0,
+					0x90,
+					0x90,
+					0x90,
+					0xF0,
+				},
+				registers: [16]uint8{126},
+				display: Display{Hires: true, Planes: [2]Plane{{
+					{0xC0, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 0x3},
+					{0x40, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 0x2},
+					{0x40, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 0x2},
+					{0x40, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 0x2},
+					{0xC0, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 00, 0x3},
+				}}},
+				pc: 2,
+			},
+		},
+		{
+			name: "16x16 sprite, hires, not aligned, no collision",
+			ip: Interpreter{
+				plane: 1,
+				memory: [65536]uint8{
+					0xFF, 0xFF,
+					0x80, 0x01,
+					0x80, 0x01,
+				},
+				registers: [16]uint8{4, 62},
+				display:   Display{Hires: true},
+			},
+			instr: newInstructionXYN(0, 1, 0),
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
+					0xFF, 0xFF,
+					0x80, 0x01,
+					0x80, 0x01,
+				},
+				registers: [16]uint8{4, 62},
+				display: display{Display{Hires: true}}.
+					set(62, [16]uint8{0x0F, 0xFF, 0xF0}).
+					set(63, [16]uint8{0x08, 0x00, 0x10}).
+					set(0, [16]uint8{0x08, 0x00, 0x10}).d,
+				pc: 2,
+			},
+		},
+		{
+			name: "5-byte sprite, both planes, collision in second plane",
+			ip: Interpreter{
+				plane: 3,
+				memory: [65536]uint8{
+					0xF0, 0x90, 0x90, 0x90, 0xF0,
+					0xFF, 0x00, 0x00, 0x00, 0x00,
 				},
+				display: Display{Planes: [2]Plane{{}, {
+					{0x01},
+				}}},
+			},
+			instr: newInstructionXYN(0, 1, 5),
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          3,
+				memory: [65536]uint8{
+					0xF0, 0x90, 0x90, 0x90, 0xF0,
+					0xFF, 0x00, 0x00, 0x00, 0x00,
+				},
+				registers: registers{}.set(vF, 1).r,
+				display: Display{Planes: [2]Plane{
+					{
+						{0xF0},
+						{0x90},
+						{0x90},
+						{0x90},
+						{0xF0},
+					},
+					{
+						{0xFE},
+					},
+				}},
 				pc: 2,
 			},
 		},
+		{
+			name: "5-byte sprite, not aligned, ClipSprites, no collision",
+			ip: Interpreter{
+				plane: 1,
+				// sprite for "0"
+				memory: [65536]uint8{
+					0xF0,
+					0x90,
+					0x90,
+					0x90,
+					0xF0,
+				},
+				registers: [16]uint8{62, 30},
+				quirks:    Quirks{ClipSprites: true},
+			},
+			instr: newInstructionXYN(0, 1, 5),
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				memory: [65536]uint8{
+					0xF0,
+					0x90,
+					0x90,
+					0x90,
+					0xF0,
+				},
+				registers: [16]uint8{62, 30},
+				quirks:    Quirks{ClipSprites: true},
+				display: display{}.
+					set(30, [16]uint8{7: 0x3}).
+					set(31, [16]uint8{7: 0x2}).d,
+				pc: 2,
+			},
+		},
+		{
+			name: "1-byte sprite, starting coordinates wrap, ClipSprites, DisplayWait",
+			ip: Interpreter{
+				plane:     1,
+				memory:    [65536]uint8{0xFF},
+				registers: [16]uint8{64 + 8, 32 + 1},
+				quirks:    Quirks{ClipSprites: true, DisplayWait: true},
+			},
+			instr: newInstructionXYN(0, 1, 1),
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				memory:         [65536]uint8{0xFF},
+				registers:      [16]uint8{64 + 8, 32 + 1},
+				quirks:         Quirks{ClipSprites: true, DisplayWait: true},
+				display:        display{}.set(1, [16]uint8{0, 0xFF}).d,
+				vblankWait:     true,
+				pc:             2,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -410,6 +683,482 @@ func TestLD_Fx29(t *testing.T) {
 	}
 }
 
+func TestSCD_00Cn(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "lores",
+			ip: Interpreter{
+				plane:   1,
+				display: display{}.set(0, [16]uint8{0xFF}).set(30, [16]uint8{0x0F}).d,
+			},
+			instr: instruction{0x00, 0xC2},
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				display:        display{}.set(2, [16]uint8{0xFF}).d,
+				pc:             instrLen,
+			},
+		},
+		{
+			name: "hires",
+			ip: Interpreter{
+				plane:   1,
+				display: display{Display{Hires: true}}.set(0, [16]uint8{0xFF}).set(30, [16]uint8{0x0F}).d,
+			},
+			instr: instruction{0x00, 0xC2},
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				display:        display{Display{Hires: true}}.set(2, [16]uint8{0xFF}).set(32, [16]uint8{0x0F}).d,
+				pc:             instrLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SCD_00Cn(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestSCR_00FB(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "lores",
+			ip: Interpreter{
+				plane:   1,
+				display: display{}.set(0, [16]uint8{0xFF, 0, 0, 0, 0, 0, 0, 0x0F}).d,
+			},
+			instr: instruction{0x00, 0xFB},
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				display:        display{}.set(0, [16]uint8{0x0F, 0xF0}).d,
+				pc:             instrLen,
+			},
+		},
+		{
+			name: "hires",
+			ip: Interpreter{
+				plane:   1,
+				display: display{Display{Hires: true}}.set(0, [16]uint8{0xFF, 0, 0, 0, 0, 0, 0, 0x0F}).d,
+			},
+			instr: instruction{0x00, 0xFB},
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				display:        display{Display{Hires: true}}.set(0, [16]uint8{0x0F, 0xF0, 0, 0, 0, 0, 0, 0, 0xF0}).d,
+				pc:             instrLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SCR_00FB(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestSCL_00FC(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "lores",
+			ip: Interpreter{
+				plane:   1,
+				display: display{}.set(0, [16]uint8{0xFF, 0, 0, 0, 0, 0, 0, 0xFF}).d,
+			},
+			instr: instruction{0x00, 0xFC},
+			expected: Interpreter{
+				displayChanged: true,
+				plane:          1,
+				display:        display{}.set(0, [16]uint8{0xF0, 0, 0, 0, 0, 0, 0x0F, 0xF0}).d,
+				pc:             instrLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SCL_00FC(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestLD_Fx30(t *testing.T) {
+	ip := Interpreter{registers: [16]uint8{0, 0, 0, 9}}
+	LD_Fx30(&ip, newInstructionXKk(3, 0x30))
+	var want uint16 = memoryOffsetBigSprites + 90
+	if ip.i != want {
+		t.Errorf("want = 0x%X, got = 0x%X", want, ip.i)
+	}
+}
+
+func TestLD_Fx75_Fx85(t *testing.T) {
+	ip := Interpreter{registers: [16]uint8{1, 2, 3, 4, 5, 6, 7, 8}}
+	LD_Fx75(&ip, newInstructionXKk(7, 0x75))
+	ip.registers = [16]uint8{}
+	LD_Fx85(&ip, newInstructionXKk(3, 0x85))
+	want := [16]uint8{1, 2, 3, 4}
+	if diff := cmp.Diff(want, ip.registers); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSE_3xkk(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "Vx != kk",
+			ip: Interpreter{
+				registers: [16]uint8{1},
+			},
+			instr: newInstructionXKk(0, 2),
+			expected: Interpreter{
+				registers: [16]uint8{1},
+				pc:        instrLen,
+			},
+		},
+		{
+			name: "Vx == kk",
+			ip: Interpreter{
+				registers: [16]uint8{1},
+			},
+			instr: newInstructionXKk(0, 1),
+			expected: Interpreter{
+				registers: [16]uint8{1},
+				pc:        2 * instrLen,
+			},
+		},
+		{
+			name: "Vx == kk, skips over F000 nnnn",
+			ip: Interpreter{
+				memory:    [65536]uint8{0x30, 0x01, 0xF0, 0x00, 0x12, 0x34},
+				registers: [16]uint8{1},
+			},
+			instr: newInstructionXKk(0, 1),
+			expected: Interpreter{
+				memory:    [65536]uint8{0x30, 0x01, 0xF0, 0x00, 0x12, 0x34},
+				registers: [16]uint8{1},
+				pc:        3 * instrLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SE_3xkk(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestLD_F000(t *testing.T) {
+	ip := Interpreter{
+		memory: [65536]uint8{0xF0, 0x00, 0xAB, 0xCD},
+	}
+	LD_F000(&ip, instruction{0xF0, 0x00})
+	if ip.i != 0xABCD {
+		t.Errorf("want = 0x%X, got = 0x%X", 0xABCD, ip.i)
+	}
+	if ip.pc != 4 {
+		t.Errorf("want = 0x%X, got = 0x%X", 4, ip.pc)
+	}
+}
+
+func TestSAVE_5xy2(t *testing.T) {
+	tests := []struct {
+		name  string
+		instr instruction
+		want  []uint8
+	}{
+		{
+			name:  "ascending",
+			instr: newInstructionXYN(0x52, 4, 2),
+			want:  []uint8{2, 3, 4},
+		},
+		{
+			name:  "descending",
+			instr: newInstructionXYN(0x54, 2, 2),
+			want:  []uint8{4, 3, 2},
+		},
+		{
+			name:  "single register",
+			instr: newInstructionXYN(0x53, 3, 2),
+			want:  []uint8{3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := Interpreter{
+				registers: [16]uint8{0, 1, 2, 3, 4, 5},
+				i:         0x300,
+			}
+			SAVE_5xy2(&ip, tt.instr)
+			if diff := cmp.Diff(tt.want, ip.memory[0x300:0x300+len(tt.want)]); diff != "" {
+				t.Error(diff)
+			}
+			if ip.i != 0x300 {
+				t.Errorf("want = 0x%X, got = 0x%X", 0x300, ip.i)
+			}
+		})
+	}
+}
+
+func TestLOAD_5xy3(t *testing.T) {
+	ip := Interpreter{
+		i: 0x300,
+	}
+	copy(ip.memory[0x300:], []uint8{7, 8, 9})
+	LOAD_5xy3(&ip, newInstructionXYN(0x5A, 8, 3))
+	want := [16]uint8{8: 9, 9: 8, 10: 7}
+	if diff := cmp.Diff(want, ip.registers); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSHR_8xy6(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "LSB == 1",
+			ip: Interpreter{
+				registers: [16]uint8{0x0F, 0x80},
+			},
+			instr: newInstructionXYN(0, 1, 6),
+			expected: Interpreter{
+				registers: registers{[16]uint8{0x07, 0x80}}.set(vF, 1).r,
+				pc:        instrLen,
+			},
+		},
+		{
+			name: "ShiftUsesVy",
+			ip: Interpreter{
+				registers: [16]uint8{0x0F, 0x80},
+				quirks:    Quirks{ShiftUsesVy: true},
+			},
+			instr: newInstructionXYN(0, 1, 6),
+			expected: Interpreter{
+				registers: [16]uint8{0x40, 0x80},
+				quirks:    Quirks{ShiftUsesVy: true},
+				pc:        instrLen,
+			},
+		},
+		{
+			name: "Vx is VF",
+			ip: Interpreter{
+				registers: registers{}.set(vF, 0x03).r,
+			},
+			instr: newInstructionXYN(vF, 0, 6),
+			expected: Interpreter{
+				registers: registers{}.set(vF, 1).r,
+				pc:        instrLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SHR_8xy6(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestOR_8xy1(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "VF unchanged",
+			ip: Interpreter{
+				registers: registers{[16]uint8{0x0F, 0xF0}}.set(vF, 1).r,
+			},
+			instr: newInstructionXYN(0, 1, 1),
+			expected: Interpreter{
+				registers: registers{[16]uint8{0xFF, 0xF0}}.set(vF, 1).r,
+				pc:        instrLen,
+			},
+		},
+		{
+			name: "VFReset",
+			ip: Interpreter{
+				registers: registers{[16]uint8{0x0F, 0xF0}}.set(vF, 1).r,
+				quirks:    Quirks{VFReset: true},
+			},
+			instr: newInstructionXYN(0, 1, 1),
+			expected: Interpreter{
+				registers: [16]uint8{0xFF, 0xF0},
+				quirks:    Quirks{VFReset: true},
+				pc:        instrLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			OR_8xy1(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestJP_Bnnn(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "V0",
+			ip: Interpreter{
+				registers: [16]uint8{0x10, 0, 0x20},
+			},
+			instr: newInstructionAddr(0xB234),
+			expected: Interpreter{
+				registers: [16]uint8{0x10, 0, 0x20},
+				pc:        0x244,
+			},
+		},
+		{
+			name: "JumpUsesVx",
+			ip: Interpreter{
+				registers: [16]uint8{0x10, 0, 0x20},
+				quirks:    Quirks{JumpUsesVx: true},
+			},
+			instr: newInstructionAddr(0xB234),
+			expected: Interpreter{
+				registers: [16]uint8{0x10, 0, 0x20},
+				quirks:    Quirks{JumpUsesVx: true},
+				pc:        0x254,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			JP_Bnnn(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestRND_Cxkk(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       Interpreter
+		instr    instruction
+		expected Interpreter
+	}{
+		{
+			name: "masked with kk",
+			ip: Interpreter{
+				random: NewFixedRandom(0xB7, 0x42),
+			},
+			instr: newInstructionXKk(3, 0x0F),
+			expected: Interpreter{
+				registers: registers{}.set(3, 0x07).r,
+				pc:        instrLen,
+				random:    &FixedRandom{bytes: []uint8{0xB7, 0x42}, next: 1},
+			},
+		},
+		{
+			name: "VIPRandom",
+			ip: Interpreter{
+				memory: [65536]uint8{0x201: 0x30},
+				random: &VIPRandom{value: 0x1000},
+			},
+			instr: newInstructionXKk(0, 0xFF),
+			expected: Interpreter{
+				memory:    [65536]uint8{0x201: 0x30},
+				registers: [16]uint8{0x40},
+				pc:        instrLen,
+				random:    &VIPRandom{value: 0x4001},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RND_Cxkk(&tt.ip, tt.instr)
+			if diff := cmp.Diff(tt.expected, tt.ip, cmp.AllowUnexported(Interpreter{}, FixedRandom{}, VIPRandom{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestLD_Fx55(t *testing.T) {
+	tests := []struct {
+		name   string
+		quirks Quirks
+		wantI  uint16
+	}{
+		{
+			name:  "I unchanged",
+			wantI: 0x300,
+		},
+		{
+			name:   "LoadStoreIncrementsI",
+			quirks: Quirks{LoadStoreIncrementsI: true},
+			wantI:  0x303,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := Interpreter{
+				registers: [16]uint8{1, 2, 3, 4},
+				i:         0x300,
+				quirks:    tt.quirks,
+			}
+			LD_Fx55(&ip, newInstructionXKk(2, 0x55))
+			if diff := cmp.Diff([]uint8{1, 2, 3, 0}, ip.memory[0x300:0x304]); diff != "" {
+				t.Error(diff)
+			}
+			if ip.i != tt.wantI {
+				t.Errorf("want = 0x%X, got = 0x%X", tt.wantI, ip.i)
+			}
+		})
+	}
+}
+
 func BenchmarkADD_8xy4(b *testing.B) {
 	ip := new(Interpreter)
 	instr := instruction{0x01, 0x10}
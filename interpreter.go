@@ -1,20 +1,44 @@
-package main
+package chip8
 
 import (
+	"errors"
 	"fmt"
-	"math/rand"
 	"time"
 )
 
 // memoryOffsetProgram is the memory address that Chip-8 programs begin at.
 const memoryOffsetProgram = 0x200
 
+// memoryOffsetBigSprites is the memory address that the SUPER-CHIP 10-byte hexadecimal font is loaded at.
+const memoryOffsetBigSprites = 0x80
+
+// bigSpriteLen is the length in bytes of a single SUPER-CHIP font sprite.
+const bigSpriteLen = 10
+
+// allPlanes selects both XO-CHIP display planes.
+const allPlanes = 0x3
+
+// defaultPitch is the initial value of the XO-CHIP pitch register, which plays audio patterns at 4000 bits per second.
+const defaultPitch = 64
+
 const (
 	// TimestepSimulation is the clock speed of the Chip-8 emulator.
 	TimestepSimulation = 2 * time.Millisecond
 
-	// TimestepBatch is the time between executing batches of instructions.
-	TimestepBatch = 100 * time.Millisecond
+	// DefaultInstructionsPerFrame is the number of instructions executed between each 60 Hz timer tick
+	// when running at the speed of TimestepSimulation.
+	DefaultInstructionsPerFrame = int(timerInterval / TimestepSimulation)
+)
+
+var (
+	// ErrExited is returned when stepping an interpreter after its program has exited.
+	ErrExited = errors.New("chip8: program has exited")
+
+	// ErrProgramTooLarge is returned by Load when a program does not fit in memory.
+	ErrProgramTooLarge = errors.New("chip8: program too large")
+
+	// ErrBreak is returned by RunFrame when the break function set with SetBreakFunc stops execution.
+	ErrBreak = errors.New("chip8: break")
 )
 
 // Sprites for the Chip-8 hexadecimal font
@@ -83,12 +107,33 @@ var (
 		// F
 		0xF0, 0x80, 0xF0, 0x80, 0x80,
 	}
+
+	// BigSprites is the SUPER-CHIP 8x10 hexadecimal font.
+	BigSprites = []uint8{
+		0xFF, 0xFF, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, // 0
+		0x18, 0x78, 0x78, 0x18, 0x18, 0x18, 0x18, 0x18, 0xFF, 0xFF, // 1
+		0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, // 2
+		0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 3
+		0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0x03, 0x03, // 4
+		0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 5
+		0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, // 6
+		0xFF, 0xFF, 0x03, 0x03, 0x06, 0x0C, 0x18, 0x18, 0x18, 0x18, // 7
+		0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, // 8
+		0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 9
+		0x7E, 0xFF, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xC3, // A
+		0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, // B
+		0x3C, 0xFF, 0xC3, 0xC0, 0xC0, 0xC0, 0xC0, 0xC3, 0xFF, 0x3C, // C
+		0xFC, 0xFE, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFE, 0xFC, // D
+		0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, // E
+		0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xC0, 0xC0, // F
+	}
 )
 
 // Interpreter contains the current state of the Chip-8 interpreter as well as its connected hardware.
 type Interpreter struct {
 	// The Chip-8 language is capable of accessing up to 4KB (4,096 bytes) of RAM.
-	memory [4096]uint8
+	// XO-CHIP extends this to 64KB (65,536 bytes).
+	memory [65536]uint8
 
 	// Chip-8 has 16 general purpose 8-bit registers.
 	registers [16]uint8
@@ -97,16 +142,9 @@ type Interpreter struct {
 	i uint16
 
 	// Chip-8 also has two special purpose 8-bit registers, for the delay and sound timers.
-	// dtget and stget are used for getting the current value of the delay and sound timers.
-	// dtset and stset are used for setting the current value of the delay and sound timers.
-	dtget <-chan uint8
-	stget <-chan uint8
-
-	dtset chan<- uint8
-	stset chan<- uint8
-
-	dtstop chan<- struct{}
-	ststop chan<- struct{}
+	// They are decremented at the end of every frame.
+	dt uint8
+	st uint8
 
 	// The program counter (PC) should be 16-bit, and is used to store the currently executing address.
 	pc uint16
@@ -118,86 +156,266 @@ type Interpreter struct {
 	stack [16]uint16
 
 	// The original implementation of the Chip-8 language used a 64x32-pixel monochrome display.
-	display [32][8]uint8
+	// SUPER-CHIP added a 128x64-pixel high resolution mode.
+	display Display
+
+	// The display is sent to displaych at the end of each frame in which it changed.
+	// displayChanged is set when the display is drawn to, and cleared at the end of the frame.
+	displaych      chan<- Display
+	displayChanged bool
+
+	// SUPER-CHIP can save registers to the HP-48 RPL user flags with Fx75 and restore them with Fx85.
+	flags [16]uint8
+
+	// XO-CHIP programs select which of the two display planes are drawn to with Fn01.
+	plane uint8
+
+	// XO-CHIP programs play back a 16-byte audio pattern buffer at a rate determined by the pitch register.
+	pattern [16]uint8
+	pitch   uint8
+
+	// audio is notified when the sound changes, and sound is the sound it was last told about.
+	audio Audio
+	sound Sound
+
+	// ipf is the number of instructions executed per frame. If it is zero, DefaultInstructionsPerFrame is used.
+	ipf int
 
-	// The current state of the display is sent to displaych whenever it is drawn.
-	displaych chan<- [32][8]uint8
+	// quirks configures the behaviour of ambiguous instructions.
+	quirks Quirks
+
+	// vblankWait is set by DRW when the DisplayWait quirk is enabled to pause execution until the next display refresh.
+	vblankWait bool
+
+	// breakFunc is called before each instruction executed by RunFrame to decide whether to stop.
+	// resuming is set after RunFrame stops so that execution can resume past the same instruction.
+	breakFunc func(ip *Interpreter) bool
+	resuming  bool
+
+	// frameFunc is called at the end of each frame.
+	frameFunc func(ip *Interpreter)
+
+	// err is the fault which stopped the interpreter, if any.
+	err error
+
+	// exited is set when the program executes the SUPER-CHIP EXIT instruction.
+	exited bool
 
 	// The computers which originally used the Chip-8 Language had a 16-key hexadecimal keypad.
-	// Each receive will return a bitmask of the currently pressed keys.
-	keypadch <-chan uint16
+	keypad Keypad
 
-	stopch chan struct{}
-}
+	// random generates the random numbers of RND. If it is nil when RND is first executed,
+	// a SeededRandom seeded from the time is used.
+	random Random
 
-func (ip *Interpreter) init() {
-	ip.stopch = make(chan struct{})
-	ip.displaych = make(chan [32][8]uint8)
+	// While Fx0A waits for the key it has seen pressed to be released, keyWait is set and waitKey is the key.
+	keyWait bool
+	waitKey uint8
+
+	stopch chan struct{}
+	donech chan struct{}
 }
 
-// New returns a new Chip-8 interpreter.
-func New(keypad <-chan uint16, display chan<- [32][8]uint8) *Interpreter {
+// New returns a new Chip-8 interpreter which handles ambiguous instructions according to quirks.
+//
+// The interpreter queries keypad for the state of the keys, and sends the contents of the display to display at the
+// end of each frame in which it changed. If keypad is nil, no keys are ever pressed, and if display is nil,
+// the display is not sent anywhere. Otherwise display must be received from until the interpreter stops.
+func New(keypad Keypad, display chan<- Display, quirks Quirks) *Interpreter {
 	return &Interpreter{
-		keypadch:  keypad,
+		keypad:    keypad,
 		displaych: display,
+		quirks:    quirks,
+		stopch:    make(chan struct{}),
+		donech:    make(chan struct{}),
+		plane:     1,
+		pitch:     defaultPitch,
 	}
 }
 
-// Load loads a Chip-8 program into memory.
-func (ip *Interpreter) Load(prog []byte) {
-	copy(ip.memory[memoryOffsetProgram:], prog)
+// Load loads a Chip-8 program into memory at 0x200 together with the font sprites,
+// and sets the program counter to the start of the program.
+//
+// If the program does not fit in the memory given by the quirks, nothing is loaded and Load returns an error
+// wrapping ErrProgramTooLarge.
+func (ip *Interpreter) Load(prog []byte) error {
+	return ip.LoadAt(prog, memoryOffsetProgram)
 }
 
-// Run starts the Chip-8 interpreter.
-func (ip *Interpreter) Run() {
-	// initialise stop channel
-	ip.stopch = make(chan struct{})
-
-	// load sprites
+// LoadAt is like Load, but loads the program at addr instead of 0x200.
+// For example, programs for the ETI-660 start at 0x600.
+func (ip *Interpreter) LoadAt(prog []byte, addr uint16) error {
+	size := ip.quirks.memorySize()
+	if int(addr) >= size {
+		return fmt.Errorf("chip8: load address 0x%03X is outside %d bytes of memory", addr, size)
+	}
+	if free := size - int(addr); len(prog) > free {
+		return fmt.Errorf("%w: %d bytes do not fit in the %d bytes from 0x%03X", ErrProgramTooLarge, len(prog), free, addr)
+	}
 	ip.loadSprites()
+	copy(ip.memory[addr:], prog)
+	ip.pc = addr
+	return nil
+}
+
+// Reset returns the interpreter to the state it was in when it was created, clearing memory, the registers and
+// the display, so that a program can be loaded again. The quirks, the number of instructions per frame and the
+// random source and its state are kept.
+func (ip *Interpreter) Reset() {
+	r, ok := ip.random.(SavableRandom)
+	var state uint64
+	if ok {
+		state = r.State()
+	}
+	ip.Restore(&Snapshot{Plane: 1, Pitch: defaultPitch})
+	if ok {
+		r.SetState(state)
+	}
+}
+
+// SetInstructionsPerFrame sets the number of instructions executed by RunFrame.
+func (ip *Interpreter) SetInstructionsPerFrame(n int) {
+	ip.ipf = n
+}
+
+// SetBreakFunc sets a function which is called before each instruction executed by RunFrame.
+// If it returns true, RunFrame stops before executing the instruction and returns ErrBreak.
+// This can be used to implement breakpoints. If f is nil, RunFrame never stops early.
+func (ip *Interpreter) SetBreakFunc(f func(ip *Interpreter) bool) {
+	ip.breakFunc = f
+}
+
+// SetFrameFunc sets a function which is called at the end of each frame executed by RunFrame, after the timers are
+// decremented and the display is sent. This is the 60 Hz vertical blank, when a display shows the frame.
+// If f is nil, nothing is called.
+func (ip *Interpreter) SetFrameFunc(f func(ip *Interpreter)) {
+	ip.frameFunc = f
+}
 
-	// start sound and delay timers
-	ip.stget, ip.stset, ip.ststop = newTimer()
-	ip.dtget, ip.dtset, ip.dtstop = newTimer()
+// SetRandom sets the source of the random numbers generated by RND.
+// If r is a SavableRandom, its state is saved in snapshots and set by Restore.
+func (ip *Interpreter) SetRandom(r Random) {
+	ip.random = r
+}
 
-	// set PC to program start address
-	ip.pc = memoryOffsetProgram
+// SetSeed makes RND use a SeededRandom seeded with seed, so that runs of a program with the same seed and the same
+// input are the same.
+func (ip *Interpreter) SetSeed(seed int64) {
+	ip.SetRandom(NewSeededRandom(seed))
+}
 
-	currentTime := time.Now()
-	var accum time.Duration
+// Run starts the Chip-8 interpreter and blocks until it is stopped with Stop or the program exits.
+//
+// Frames are executed with RunFrame in real time at 60 Hz. If RunFrame returns an error, the interpreter stops
+// and Run returns the error.
+func (ip *Interpreter) Run() error {
+	defer ip.shutdown()
 
-	ticker := time.NewTicker(TimestepBatch)
+	ticker := time.NewTicker(timerInterval)
 	defer ticker.Stop()
 	for {
 		select {
-		case newTime := <-ticker.C:
-			frameTime := newTime.Sub(currentTime)
-			currentTime = newTime
-			accum += frameTime
-			for accum >= TimestepSimulation {
-				ip.step()
-				accum -= TimestepSimulation
+		case <-ticker.C:
+			if err := ip.RunFrame(); err != nil {
+				return err
+			}
+			if ip.exited {
+				return nil
 			}
 		case <-ip.stopch:
-			// stop delay and sound timers
-			ip.ststop <- struct{}{}
-			ip.dtstop <- struct{}{}
-			close(ip.displaych)
-			return
+			return nil
 		}
 	}
 }
 
+// Stop stops the Chip-8 interpreter if it is still running.
+// It must only be called on an interpreter created with New.
 func (ip *Interpreter) Stop() {
-	ip.stopch <- struct{}{}
+	select {
+	case ip.stopch <- struct{}{}:
+	case <-ip.donech:
+	}
 }
 
+func (ip *Interpreter) shutdown() {
+	if ip.displaych != nil {
+		close(ip.displaych)
+	}
+	close(ip.donech)
+}
+
+// Step executes the instruction at the program counter.
+//
+// If the instruction cannot be executed, Step returns a *Fault and the interpreter stops.
+// Step must not be called while Run is executing. The delay and sound timers are not affected by Step.
+func (ip *Interpreter) Step() error {
+	ip.resuming = false
+	if ip.err != nil {
+		return ip.err
+	}
+	if ip.exited {
+		return ErrExited
+	}
+	ip.err = ip.step()
+	return ip.err
+}
+
+// RunFrame executes the instructions for one 60 Hz frame, and then decrements the delay and sound timers.
+// At the end of the frame, the display is sent if it has changed and the frame function is called.
+//
+// The number of instructions executed is set with SetInstructionsPerFrame. Fewer instructions are executed
+// if the program exits, or if it draws a sprite while the DisplayWait quirk is enabled.
+//
+// If the break function set with SetBreakFunc stops execution, RunFrame returns ErrBreak without decrementing
+// the timers. The next call to RunFrame resumes execution from the same instruction.
+//
+// RunFrame must not be called while Run is executing.
+func (ip *Interpreter) RunFrame() error {
+	n := ip.ipf
+	if n == 0 {
+		n = DefaultInstructionsPerFrame
+	}
+	for i := 0; i < n && !ip.exited; i++ {
+		if ip.breakFunc != nil && !ip.resuming && ip.breakFunc(ip) {
+			ip.resuming = true
+			return ErrBreak
+		}
+		if err := ip.Step(); err != nil {
+			return err
+		}
+		if ip.vblankWait {
+			// execution continues at the next display refresh
+			ip.vblankWait = false
+			break
+		}
+	}
+	ip.tickTimers()
+	ip.vblank()
+	return nil
+}
+
+// vblank ends a frame by sending the display if it has changed and calling the frame function.
+func (ip *Interpreter) vblank() {
+	if ip.displayChanged && ip.displaych != nil {
+		ip.displaych <- ip.display
+	}
+	ip.displayChanged = false
+	if ip.frameFunc != nil {
+		ip.frameFunc(ip)
+	}
+}
+
+// render marks the display as changed, so that it is sent at the end of the frame.
 func (ip *Interpreter) render() {
-	// non blocking send to the display
-	select {
-	case ip.displaych <- ip.display:
-	default:
+	ip.displayChanged = true
+}
+
+// keys returns a bitmask of the currently pressed keys, or no keys if the interpreter has no keypad.
+func (ip *Interpreter) keys() uint16 {
+	if ip.keypad == nil {
+		return 0
 	}
+	return ip.keypad.Keys()
 }
 
 func (ip *Interpreter) currentInstr() instruction {
@@ -207,92 +425,141 @@ func (ip *Interpreter) currentInstr() instruction {
 	}
 }
 
-func (ip *Interpreter) step() {
+// skip advances the program counter past the instruction at the program counter.
+//
+// XO-CHIP: F000 nnnn is 4 bytes long, so it is skipped as a whole.
+func (ip *Interpreter) skip() {
+	if ip.memory[ip.pc] == 0xF0 && ip.memory[ip.pc+1] == 0x00 {
+		ip.pc += 2 * instrLen
+		return
+	}
+	ip.pc += instrLen
+}
+
+func (ip *Interpreter) step() error {
 	instr := ip.currentInstr()
+	if err := ip.checkMemory(ip.pc, instrLen, instr); err != nil {
+		// the program counter has run off the end of memory
+		return err
+	}
 	op := instr.opcode()
 	// log.Printf("opcode: %d, instr: 0x%02X%02X, pc: 0x%02X", op, instr.hi, instr.lo, ip.pc)
 	switch op {
 	case OpCLS_00E0:
-		CLS_00E0(ip, instr)
+		return CLS_00E0(ip, instr)
 	case OpRET_00EE:
-		RET_00EE(ip, instr)
+		return RET_00EE(ip, instr)
 	case OpSYS_0nnn:
-		SYS_0nnn(ip, instr)
+		return SYS_0nnn(ip, instr)
 	case OpJP_1nnn:
-		JP_1nnn(ip, instr)
+		return JP_1nnn(ip, instr)
 	case OpCALL_2nnn:
-		CALL_2nnn(ip, instr)
+		return CALL_2nnn(ip, instr)
 	case OpSE_3xkk:
-		SE_3xkk(ip, instr)
+		return SE_3xkk(ip, instr)
 	case OpSNE_4xkk:
-		SNE_4xkk(ip, instr)
+		return SNE_4xkk(ip, instr)
 	case OpSE_5xy0:
-		SE_5xy0(ip, instr)
+		return SE_5xy0(ip, instr)
 	case OpLD_6xkk:
-		LD_6xkk(ip, instr)
+		return LD_6xkk(ip, instr)
 	case OpADD_7xkk:
-		ADD_7xkk(ip, instr)
+		return ADD_7xkk(ip, instr)
 	case OpLD_8xy0:
-		LD_8xy0(ip, instr)
+		return LD_8xy0(ip, instr)
 	case OpOR_8xy1:
-		OR_8xy1(ip, instr)
+		return OR_8xy1(ip, instr)
 	case OpAND_8xy2:
-		AND_8xy2(ip, instr)
+		return AND_8xy2(ip, instr)
 	case OpXOR_8xy3:
-		XOR_8xy3(ip, instr)
+		return XOR_8xy3(ip, instr)
 	case OpADD_8xy4:
-		ADD_8xy4(ip, instr)
+		return ADD_8xy4(ip, instr)
 	case OpSUB_8xy5:
-		SUB_8xy5(ip, instr)
+		return SUB_8xy5(ip, instr)
 	case OpSHR_8xy6:
-		SHR_8xy6(ip, instr)
+		return SHR_8xy6(ip, instr)
 	case OpSUBN_8xy7:
-		SUBN_8xy7(ip, instr)
+		return SUBN_8xy7(ip, instr)
 	case OpSHL_8xyE:
-		SHL_8xyE(ip, instr)
+		return SHL_8xyE(ip, instr)
 	case OpSNE_9xy0:
-		SNE_9xy0(ip, instr)
+		return SNE_9xy0(ip, instr)
 	case OpLD_Annn:
-		LD_Annn(ip, instr)
+		return LD_Annn(ip, instr)
 	case OpJP_Bnnn:
-		JP_Bnnn(ip, instr)
+		return JP_Bnnn(ip, instr)
 	case OpRND_Cxkk:
-		RND_Cxkk(ip, instr)
+		return RND_Cxkk(ip, instr)
 	case OpDRW_Dxyn:
-		DRW_Dxyn(ip, instr)
+		return DRW_Dxyn(ip, instr)
 	case OpSKP_Ex9E:
-		SKP_Ex9E(ip, instr)
+		return SKP_Ex9E(ip, instr)
 	case OpSKNP_ExA1:
-		SKNP_ExA1(ip, instr)
+		return SKNP_ExA1(ip, instr)
 	case OpLD_Fx07:
-		LD_Fx07(ip, instr)
+		return LD_Fx07(ip, instr)
 	case OpLD_Fx0A:
-		LD_Fx0A(ip, instr)
+		return LD_Fx0A(ip, instr)
 	case OpLD_Fx15:
-		LD_Fx15(ip, instr)
+		return LD_Fx15(ip, instr)
 	case OpLD_Fx18:
-		LD_Fx18(ip, instr)
+		return LD_Fx18(ip, instr)
 	case OpADD_Fx1E:
-		ADD_Fx1E(ip, instr)
+		return ADD_Fx1E(ip, instr)
 	case OpLD_Fx29:
-		LD_Fx29(ip, instr)
+		return LD_Fx29(ip, instr)
 	case OpLD_Fx33:
-		LD_Fx33(ip, instr)
+		return LD_Fx33(ip, instr)
 	case OpLD_Fx55:
-		LD_Fx55(ip, instr)
+		return LD_Fx55(ip, instr)
 	case OpLD_Fx65:
-		LD_Fx65(ip, instr)
+		return LD_Fx65(ip, instr)
+	case OpSCD_00Cn:
+		return SCD_00Cn(ip, instr)
+	case OpSCR_00FB:
+		return SCR_00FB(ip, instr)
+	case OpSCL_00FC:
+		return SCL_00FC(ip, instr)
+	case OpEXIT_00FD:
+		return EXIT_00FD(ip, instr)
+	case OpLOW_00FE:
+		return LOW_00FE(ip, instr)
+	case OpHIGH_00FF:
+		return HIGH_00FF(ip, instr)
+	case OpLD_Fx30:
+		return LD_Fx30(ip, instr)
+	case OpLD_Fx75:
+		return LD_Fx75(ip, instr)
+	case OpLD_Fx85:
+		return LD_Fx85(ip, instr)
+	case OpSCU_00Dn:
+		return SCU_00Dn(ip, instr)
+	case OpSAVE_5xy2:
+		return SAVE_5xy2(ip, instr)
+	case OpLOAD_5xy3:
+		return LOAD_5xy3(ip, instr)
+	case OpLD_F000:
+		return LD_F000(ip, instr)
+	case OpPLANE_Fn01:
+		return PLANE_Fn01(ip, instr)
+	case OpAUDIO_F002:
+		return AUDIO_F002(ip, instr)
+	case OpPITCH_Fx3A:
+		return PITCH_Fx3A(ip, instr)
 	default:
-		panic(fmt.Sprintf("illegal opcode: 0x%X", op))
+		return ip.fault(IllegalOpcode, instr)
 	}
 }
 
 func (ip *Interpreter) rand() uint8 {
-	b := make([]byte, 1)
-	rand.Read(b)
-	return b[0]
+	if ip.random == nil {
+		ip.SetSeed(time.Now().UnixNano())
+	}
+	return ip.random.Byte(ip)
 }
 
 func (ip *Interpreter) loadSprites() {
 	copy(ip.memory[:], Sprites)
+	copy(ip.memory[memoryOffsetBigSprites:], BigSprites)
 }
@@ -0,0 +1,408 @@
+package chip8
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInterpreter_Step(t *testing.T) {
+	ip := New(nil, nil, QuirksXOCHIP)
+	ip.Load([]byte{
+		0x60, 0x05, // LD V0, 0x05
+		0x61, 0x03, // LD V1, 0x03
+		0x80, 0x14, // ADD V0, V1
+		0xF0, 0x15, // LD DT, V0
+	})
+	for i := 0; i < 4; i++ {
+		if err := ip.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := ip.Registers()[0]; got != 8 {
+		t.Errorf("V0: want = %d, got = %d", 8, got)
+	}
+	if got := ip.DT(); got != 8 {
+		t.Errorf("DT: want = %d, got = %d", 8, got)
+	}
+	if got, want := ip.PC(), uint16(memoryOffsetProgram+8); got != want {
+		t.Errorf("PC: want = 0x%X, got = 0x%X", want, got)
+	}
+}
+
+func TestInterpreter_RunFrame(t *testing.T) {
+	ip := New(nil, nil, QuirksXOCHIP)
+	ip.SetInstructionsPerFrame(3)
+	ip.Load([]byte{
+		0x60, 0x05, // LD V0, 0x05
+		0xF0, 0x15, // LD DT, V0
+		0xF0, 0x18, // LD ST, V0
+		0x70, 0x01, // ADD V0, 0x01
+	})
+	if err := ip.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ip.DT(); got != 4 {
+		t.Errorf("DT: want = %d, got = %d", 4, got)
+	}
+	if got := ip.ST(); got != 4 {
+		t.Errorf("ST: want = %d, got = %d", 4, got)
+	}
+	if got, want := ip.PC(), uint16(memoryOffsetProgram+6); got != want {
+		t.Errorf("PC: want = 0x%X, got = 0x%X", want, got)
+	}
+}
+
+func TestInterpreter_RunFrame_DisplayWait(t *testing.T) {
+	ip := New(nil, nil, QuirksCOSMACVIP)
+	ip.Load([]byte{
+		0xD0, 0x01, // DRW V0, V0, 1
+		0x70, 0x01, // ADD V0, 0x01
+		0x12, 0x00, // JP 0x200
+	})
+	for i := 0; i < 3; i++ {
+		if err := ip.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := ip.Registers()[0]; got != 2 {
+		t.Errorf("V0: want = %d, got = %d", 2, got)
+	}
+}
+
+func TestInterpreter_RunFrame_vblank(t *testing.T) {
+	display := make(chan Display, 2)
+	ip := New(nil, display, QuirksXOCHIP)
+	ip.SetInstructionsPerFrame(4)
+	ip.Load([]byte{
+		0xD0, 0x01, // DRW V0, V0, 1
+		0xD0, 0x01, // DRW V0, V0, 1
+		0xD0, 0x01, // DRW V0, V0, 1
+		0x12, 0x06, // JP 0x206
+	})
+	var frames int
+	ip.SetFrameFunc(func(ip *Interpreter) {
+		frames++
+	})
+	for i := 0; i < 2; i++ {
+		if err := ip.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if frames != 2 {
+		t.Errorf("frames: want = 2, got = %d", frames)
+	}
+	// the display is only sent at the end of the first frame, after the last DRW
+	if got := len(display); got != 1 {
+		t.Fatalf("displays sent: want = 1, got = %d", got)
+	}
+	if d := <-display; d.Pixel(0, 0) != 1 {
+		t.Error("want the display from the end of the frame")
+	}
+}
+
+func TestInterpreter_Step_exited(t *testing.T) {
+	ip := New(nil, nil, QuirksSUPERCHIP)
+	ip.Load([]byte{
+		0x00, 0xFD, // EXIT
+	})
+	if err := ip.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if !ip.Exited() {
+		t.Error("want exited")
+	}
+	if err := ip.Step(); err != ErrExited {
+		t.Errorf("want = %v, got = %v", ErrExited, err)
+	}
+}
+
+func TestInterpreter_Step_faults(t *testing.T) {
+	tests := []struct {
+		name   string
+		quirks Quirks
+		prog   []byte
+		want   Fault
+	}{
+		{
+			name: "illegal opcode",
+			prog: []byte{
+				0xFF, 0xFF,
+			},
+			want: Fault{Kind: IllegalOpcode, PC: 0x200, Instr: 0xFFFF},
+		},
+		{
+			name: "stack underflow",
+			prog: []byte{
+				0x00, 0xEE, // RET
+			},
+			want: Fault{Kind: StackUnderflow, PC: 0x200, Instr: 0x00EE},
+		},
+		{
+			name: "stack overflow",
+			prog: []byte{
+				0x22, 0x00, // CALL 0x200
+			},
+			want: Fault{Kind: StackOverflow, PC: 0x200, Instr: 0x2200},
+		},
+		{
+			name:   "sprite past end of memory",
+			quirks: QuirksXOCHIP,
+			prog: []byte{
+				0xF0, 0x00, 0xFF, 0xFE, // LD I, long 0xFFFE
+				0xD0, 0x05, // DRW V0, V0, 5
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x204, Instr: 0xD005},
+		},
+		{
+			name:   "BCD past end of memory",
+			quirks: QuirksXOCHIP,
+			prog: []byte{
+				0xF0, 0x00, 0xFF, 0xFF, // LD I, long 0xFFFF
+				0xF0, 0x33, // LD B, V0
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x204, Instr: 0xF033},
+		},
+		{
+			name:   "sprite past end of 4 KB memory",
+			quirks: Quirks{},
+			prog: []byte{
+				0xAF, 0xFE, // LD I, 0xFFE
+				0xD0, 0x05, // DRW V0, V0, 5
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0xD005},
+		},
+		{
+			name:   "store past end of 4 KB memory",
+			quirks: Quirks{},
+			prog: []byte{
+				0xAF, 0xFF, // LD I, 0xFFF
+				0xF1, 0x55, // LD [I], V1
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0xF155},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(nil, nil, tt.quirks)
+			ip.Load(tt.prog)
+			var err error
+			for i := 0; i < 100 && err == nil; i++ {
+				err = ip.Step()
+			}
+			var fault *Fault
+			if !errors.As(err, &fault) {
+				t.Fatalf("want fault, got = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, *fault); diff != "" {
+				t.Error(diff)
+			}
+			if !errors.Is(err, tt.want.Kind) {
+				t.Errorf("errors.Is(err, %v) = false", tt.want.Kind)
+			}
+			if err := ip.RunFrame(); err != fault {
+				t.Errorf("RunFrame after fault: want = %v, got = %v", fault, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Step_faults_memorySize(t *testing.T) {
+	profiles := []struct {
+		name   string
+		quirks Quirks
+	}{
+		{"COSMAC VIP", QuirksCOSMACVIP},
+		{"CHIP-48", QuirksCHIP48},
+		{"SUPER-CHIP", QuirksSUPERCHIP},
+	}
+	tests := []struct {
+		name string
+		prog []byte
+		want Fault
+	}{
+		{
+			name: "DRW",
+			prog: []byte{
+				0xAF, 0xFC, // LD I, 0xFFC
+				0xD0, 0x05, // DRW V0, V0, 5
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0xD005},
+		},
+		{
+			name: "Fx33",
+			prog: []byte{
+				0xAF, 0xFE, // LD I, 0xFFE
+				0xF0, 0x33, // LD B, V0
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0xF033},
+		},
+		{
+			name: "Fx55",
+			prog: []byte{
+				0xAF, 0xFF, // LD I, 0xFFF
+				0xF1, 0x55, // LD [I], V1
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0xF155},
+		},
+		{
+			name: "Fx65",
+			prog: []byte{
+				0xAF, 0xFF, // LD I, 0xFFF
+				0xF1, 0x65, // LD V1, [I]
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0xF165},
+		},
+		{
+			name: "5xy2",
+			prog: []byte{
+				0xAF, 0xFF, // LD I, 0xFFF
+				0x50, 0x12, // SAVE V0 - V1
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0x5012},
+		},
+		{
+			name: "5xy3",
+			prog: []byte{
+				0xAF, 0xFF, // LD I, 0xFFF
+				0x50, 0x13, // LOAD V0 - V1
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0x5013},
+		},
+		{
+			name: "F002",
+			prog: []byte{
+				0xAF, 0xF8, // LD I, 0xFF8
+				0xF0, 0x02, // AUDIO
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0x202, Instr: 0xF002},
+		},
+		{
+			name: "program counter",
+			prog: []byte{
+				0x1F, 0xFF, // JP 0xFFF
+			},
+			want: Fault{Kind: MemoryOutOfBounds, PC: 0xFFF, Instr: 0x0000},
+		},
+	}
+	for _, p := range profiles {
+		for _, tt := range tests {
+			t.Run(p.name+"/"+tt.name, func(t *testing.T) {
+				ip := New(nil, nil, p.quirks)
+				ip.Load(tt.prog)
+				var err error
+				for i := 0; i < 100 && err == nil; i++ {
+					err = ip.Step()
+				}
+				var fault *Fault
+				if !errors.As(err, &fault) {
+					t.Fatalf("want fault, got = %v", err)
+				}
+				if diff := cmp.Diff(tt.want, *fault); diff != "" {
+					t.Error(diff)
+				}
+			})
+		}
+	}
+}
+
+func TestInterpreter_RunFrame_break(t *testing.T) {
+	ip := New(nil, nil, QuirksXOCHIP)
+	ip.SetInstructionsPerFrame(10)
+	ip.Load([]byte{
+		0x70, 0x01, // ADD V0, 0x01
+		0x12, 0x00, // JP 0x200
+	})
+	ip.SetBreakFunc(func(ip *Interpreter) bool {
+		return ip.PC() == 0x202
+	})
+	for want := uint8(1); want <= 3; want++ {
+		if err := ip.RunFrame(); err != ErrBreak {
+			t.Fatalf("want = %v, got = %v", ErrBreak, err)
+		}
+		if got := ip.Registers()[0]; got != want {
+			t.Errorf("V0: want = %d, got = %d", want, got)
+		}
+	}
+}
+
+func TestInterpreter_Reset(t *testing.T) {
+	ip := New(nil, nil, QuirksXOCHIP)
+	ip.SetSeed(1)
+	ip.Load([]byte{0x60, 0x05, 0x00, 0xE0, 0x00, 0xFD})
+	for !ip.Exited() {
+		if err := ip.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ip.Reset()
+	want := New(nil, nil, QuirksXOCHIP)
+	want.SetSeed(1)
+	if diff := cmp.Diff(want.Snapshot(), ip.Snapshot()); diff != "" {
+		t.Errorf("Reset() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestInterpreter_Load(t *testing.T) {
+	tests := []struct {
+		name    string
+		quirks  Quirks
+		size    int
+		addr    uint16
+		wantErr error
+	}{
+		{
+			name:   "fills COSMAC VIP memory",
+			quirks: QuirksCOSMACVIP,
+			size:   0x1000 - 0x200,
+			addr:   0x200,
+		},
+		{
+			name:    "too large for COSMAC VIP memory",
+			quirks:  QuirksCOSMACVIP,
+			size:    0x1000 - 0x200 + 1,
+			addr:    0x200,
+			wantErr: ErrProgramTooLarge,
+		},
+		{
+			name:    "too large for SUPER-CHIP memory",
+			quirks:  QuirksSUPERCHIP,
+			size:    0x1000,
+			addr:    0x200,
+			wantErr: ErrProgramTooLarge,
+		},
+		{
+			name:   "fits in XO-CHIP memory",
+			quirks: QuirksXOCHIP,
+			size:   0x1000,
+			addr:   0x200,
+		},
+		{
+			name:    "too large for XO-CHIP memory",
+			quirks:  QuirksXOCHIP,
+			size:    0x10000 - 0x200 + 1,
+			addr:    0x200,
+			wantErr: ErrProgramTooLarge,
+		},
+		{
+			name:    "too large at ETI-660 load address",
+			quirks:  QuirksCOSMACVIP,
+			size:    0x1000 - 0x600 + 1,
+			addr:    0x600,
+			wantErr: ErrProgramTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(nil, nil, tt.quirks)
+			err := ip.LoadAt(make([]byte, tt.size), tt.addr)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want = %v, got = %v", tt.wantErr, err)
+			}
+			if err == nil && ip.PC() != tt.addr {
+				t.Errorf("PC: want = 0x%03X, got = 0x%03X", tt.addr, ip.PC())
+			}
+		})
+	}
+}
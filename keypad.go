@@ -1,12 +1,18 @@
-package main
+package chip8
 
 import (
+	"sync"
 	"time"
 )
 
-const (
-	KeyResetInterval = 50 * time.Millisecond
-)
+// DefaultHoldTime is how long a Keyboard holds a key after a repeat from a keyboard which does not send release
+// events. It is longer than the interval between key repeats in most terminals.
+const DefaultHoldTime = 150 * time.Millisecond
+
+// DefaultRepeatDelay is how long a Keyboard holds a key after it is first pressed on a keyboard which does not send
+// release events. It is longer than the delay before the first key repeat in most terminals, which is usually
+// between 250 and 600 ms, so that a held key stays down until its repeats arrive.
+const DefaultRepeatDelay = 600 * time.Millisecond
 
 var (
 	QwertyLayout = "x123qweasdzc4rfv"
@@ -44,28 +50,115 @@ func NewKeymap(layout string) Keymap {
 	return keymap
 }
 
-// NewKeypad returns a pair of channels, the first sending incoming keyboard events
-// and the second for reading the current keyboard state.
-func NewKeypad(keymap Keymap) (chan<- rune, <-chan uint16) {
-	var state uint16
-	keych := make(chan rune)
-	statech := make(chan uint16)
-	ticker := time.NewTicker(KeyResetInterval)
-
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				// reset pressed keys every KeyResetInterval
-				state = 0
-			case key := <-keych:
-				if mask, ok := keymap[key]; ok {
-					state |= mask
-				}
-			case statech <- state:
-			}
+// Keypad is the 16-key hexadecimal keypad, which the interpreter queries whenever an instruction needs the state of
+// the keys.
+type Keypad interface {
+	// Keys returns a bitmask of the keys which are held down, with bit i set if key i is held.
+	Keys() uint16
+}
+
+// Keyboard is a Keypad driven by key events from a keyboard, such as a terminal.
+//
+// Each key of the keypad is a small state machine. A key starts up. A press holds it down for RepeatDelay, which
+// covers the keyboard's delay before it starts repeating a held key, and each key repeat holds it for at least
+// HoldTime more. Terminals usually only send presses, so the hold timer stands in for a release, and a tapped key
+// stays down for RepeatDelay. Once a release event has been seen for a key, the keyboard is known to send them,
+// and from then on a press holds the key until it is released.
+//
+// A Keyboard is safe to use from multiple goroutines.
+type Keyboard struct {
+	// RepeatDelay is how long a key is held after it is first pressed, and HoldTime is how long it is held after
+	// a repeat, on a keyboard which does not send release events.
+	RepeatDelay time.Duration
+	HoldTime    time.Duration
+
+	keymap Keymap
+	now    func() time.Time
+
+	mu   sync.Mutex
+	keys [16]keyState
+}
+
+// keyState is the state of a single key of a Keyboard.
+type keyState struct {
+	// held is set while a key which sends release events is down.
+	held bool
+
+	// until is when a key which does not send release events comes up.
+	until time.Time
+
+	// releases is set once a release event has been seen for the key.
+	releases bool
+}
+
+// NewKeyboard returns a keypad driven by the keys in keymap. keymap may be nil if the keyboard is only driven with
+// PressKeys and ReleaseKeys.
+func NewKeyboard(keymap Keymap) *Keyboard {
+	return &Keyboard{
+		RepeatDelay: DefaultRepeatDelay,
+		HoldTime:    DefaultHoldTime,
+		keymap:      keymap,
+		now:         time.Now,
+	}
+}
+
+// Press handles a key being pressed or repeated. Keys which are not in the keymap are ignored.
+func (k *Keyboard) Press(r rune) {
+	k.PressKeys(k.keymap[r])
+}
+
+// Release handles a key being released. Keys which are not in the keymap are ignored.
+func (k *Keyboard) Release(r rune) {
+	k.ReleaseKeys(k.keymap[r])
+}
+
+// PressKeys handles a press or repeat of a keyboard key which is mapped to the keypad keys in the bitmask keys,
+// for keys which are not identified by a rune, such as the arrow keys.
+func (k *Keyboard) PressKeys(keys uint16) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	k.each(keys, func(key *keyState) {
+		switch {
+		case key.releases:
+			key.held = true
+		case !now.Before(key.until):
+			key.until = now.Add(k.RepeatDelay)
+		case now.Add(k.HoldTime).After(key.until):
+			// a repeat only ever extends the hold
+			key.until = now.Add(k.HoldTime)
 		}
-	}()
+	})
+}
 
-	return keych, statech
+// ReleaseKeys handles a release of a keyboard key which is mapped to the keypad keys in the bitmask keys.
+func (k *Keyboard) ReleaseKeys(keys uint16) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.each(keys, func(key *keyState) {
+		*key = keyState{releases: true}
+	})
+}
+
+// Keys returns a bitmask of the keys which are held down.
+func (k *Keyboard) Keys() uint16 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	var keys uint16
+	for i, key := range k.keys {
+		if key.held || now.Before(key.until) {
+			keys |= 1 << uint(i)
+		}
+	}
+	return keys
+}
+
+// each calls f for the state of each keypad key in the bitmask keys.
+func (k *Keyboard) each(keys uint16, f func(key *keyState)) {
+	for i := range k.keys {
+		if keys&(1<<uint(i)) != 0 {
+			f(&k.keys[i])
+		}
+	}
 }
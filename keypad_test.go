@@ -0,0 +1,54 @@
+package chip8
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyboard(t *testing.T) {
+	var now time.Time
+	k := NewKeyboard(NewKeymap(QwertyLayout))
+	k.now = func() time.Time {
+		return now
+	}
+	steps := []struct {
+		name    string
+		advance time.Duration
+		press   rune
+		release rune
+		want    uint16
+
+		pressKeys, releaseKeys uint16
+	}{
+		{name: "press", press: 'w', want: 1 << 5},
+		{name: "held until the first repeat", advance: DefaultRepeatDelay - 1, want: 1 << 5},
+		{name: "repeat", press: 'w', want: 1 << 5},
+		{name: "held after repeat", advance: DefaultHoldTime - 1, want: 1 << 5},
+		{name: "hold timer expires", advance: 1, want: 0},
+		{name: "tap", press: 'w', want: 1 << 5},
+		{name: "early repeat", advance: DefaultHoldTime, press: 'w', want: 1 << 5},
+		{name: "held for the repeat delay", advance: DefaultRepeatDelay - DefaultHoldTime - 1, want: 1 << 5},
+		{name: "tap comes up", advance: 1, want: 0},
+		{name: "unmapped key", press: 'p', want: 0},
+		{name: "release", press: 'x', release: 'x', want: 0},
+		{name: "press with releases", press: 'x', want: 1 << 0},
+		{name: "held until release", advance: time.Hour, want: 1 << 0},
+		{name: "released", release: 'x', want: 0},
+		{name: "press keys", pressKeys: 1<<4 | 1<<6, want: 1<<4 | 1<<6},
+		{name: "release keys", releaseKeys: 1 << 4, want: 1 << 6},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.press != 0 {
+			k.Press(step.press)
+		}
+		if step.release != 0 {
+			k.Release(step.release)
+		}
+		k.PressKeys(step.pressKeys)
+		k.ReleaseKeys(step.releaseKeys)
+		if got := k.Keys(); got != step.want {
+			t.Errorf("%s: want = %016b, got = %016b", step.name, step.want, got)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package chip8
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+// movieMagic identifies a serialized movie.
+var movieMagic = [4]byte{'C', '8', 'M', 'V'}
+
+// MovieVersion is the version of the movie format written by Movie.MarshalBinary.
+const MovieVersion = 1
+
+// Errors returned when decoding and playing movies.
+var (
+	ErrMovieFormat  = errors.New("chip8: not a movie")
+	ErrMovieVersion = errors.New("chip8: unsupported movie version")
+	ErrMovieROM     = errors.New("chip8: movie was recorded with a different program")
+)
+
+// ROMHash returns the SHA-1 hash of a program, which identifies it in movies.
+func ROMHash(prog []byte) [sha1.Size]byte {
+	return sha1.Sum(prog)
+}
+
+// Movie is a recording of the keys held down in each frame of a run of a program, together with everything else
+// needed to replay the run exactly: the program, the settings of the interpreter and the seed of its random number
+// generator.
+//
+// A movie is serialized as a big-endian binary encoding of its fields in the order they are declared,
+// preceded by the 4 bytes "C8MV" and a 2-byte format version. The quirks are encoded as a byte for each flag and
+// a 4-byte memory size, the instructions per frame as 4 bytes, and the keys as a 4-byte count of frames followed
+// by the keys of each frame.
+type Movie struct {
+	ROMHash              [sha1.Size]byte
+	LoadAddress          uint16
+	Quirks               Quirks
+	InstructionsPerFrame int
+	Seed                 int64
+
+	// Keys holds the bitmask of keys held down in each frame.
+	Keys []uint16
+}
+
+type movieHeader struct {
+	Magic   [4]byte
+	Version uint16
+}
+
+// movieSettings is the encoding of the fields of a movie before the keys.
+type movieSettings struct {
+	ROMHash              [sha1.Size]byte
+	LoadAddress          uint16
+	ShiftUsesVy          bool
+	LoadStoreIncrementsI bool
+	JumpUsesVx           bool
+	ClipSprites          bool
+	VFReset              bool
+	DisplayWait          bool
+	MemorySize           uint32
+	InstructionsPerFrame uint32
+	Seed                 int64
+	Frames               uint32
+}
+
+// Check returns ErrMovieROM if the movie was not recorded with prog.
+func (m *Movie) Check(prog []byte) error {
+	if ROMHash(prog) != m.ROMHash {
+		return ErrMovieROM
+	}
+	return nil
+}
+
+// MarshalBinary encodes the movie in the current movie format.
+func (m *Movie) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	header := movieHeader{
+		Magic:   movieMagic,
+		Version: MovieVersion,
+	}
+	settings := movieSettings{
+		ROMHash:              m.ROMHash,
+		LoadAddress:          m.LoadAddress,
+		ShiftUsesVy:          m.Quirks.ShiftUsesVy,
+		LoadStoreIncrementsI: m.Quirks.LoadStoreIncrementsI,
+		JumpUsesVx:           m.Quirks.JumpUsesVx,
+		ClipSprites:          m.Quirks.ClipSprites,
+		VFReset:              m.Quirks.VFReset,
+		DisplayWait:          m.Quirks.DisplayWait,
+		MemorySize:           uint32(m.Quirks.MemorySize),
+		InstructionsPerFrame: uint32(m.InstructionsPerFrame),
+		Seed:                 m.Seed,
+		Frames:               uint32(len(m.Keys)),
+	}
+	for _, field := range []interface{}{header, settings, m.Keys} {
+		if err := binary.Write(&buf, binary.BigEndian, field); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a movie encoded by MarshalBinary.
+func (m *Movie) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	var header movieHeader
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil || header.Magic != movieMagic {
+		return ErrMovieFormat
+	}
+	if header.Version != MovieVersion {
+		return ErrMovieVersion
+	}
+	var settings movieSettings
+	if err := binary.Read(r, binary.BigEndian, &settings); err != nil {
+		return ErrMovieFormat
+	}
+	if uint64(r.Len()) != 2*uint64(settings.Frames) {
+		return ErrMovieFormat
+	}
+	keys := make([]uint16, settings.Frames)
+	if err := binary.Read(r, binary.BigEndian, keys); err != nil {
+		return ErrMovieFormat
+	}
+	*m = Movie{
+		ROMHash:     settings.ROMHash,
+		LoadAddress: settings.LoadAddress,
+		Quirks: Quirks{
+			ShiftUsesVy:          settings.ShiftUsesVy,
+			LoadStoreIncrementsI: settings.LoadStoreIncrementsI,
+			JumpUsesVx:           settings.JumpUsesVx,
+			ClipSprites:          settings.ClipSprites,
+			VFReset:              settings.VFReset,
+			DisplayWait:          settings.DisplayWait,
+			MemorySize:           int(settings.MemorySize),
+		},
+		InstructionsPerFrame: int(settings.InstructionsPerFrame),
+		Seed:                 settings.Seed,
+		Keys:                 keys,
+	}
+	return nil
+}
+
+// MovieRecorder is a Keypad which records the keys held down on another keypad into a movie.
+//
+// The keys are sampled once per frame, when the interpreter first queries them or at the end of the frame if it
+// does not, and stay the same for the rest of the frame, so that replaying the movie queries the same keys at the
+// same instructions. Frame must be called at the end of every frame, by passing it to SetFrameFunc.
+type MovieRecorder struct {
+	movie   *Movie
+	keypad  Keypad
+	keys    uint16
+	sampled bool
+}
+
+// NewMovieRecorder returns a recorder which appends the keys held down on keypad to movie.
+func NewMovieRecorder(movie *Movie, keypad Keypad) *MovieRecorder {
+	return &MovieRecorder{
+		movie:  movie,
+		keypad: keypad,
+	}
+}
+
+// Keys returns the keys held down in the current frame.
+func (r *MovieRecorder) Keys() uint16 {
+	if !r.sampled {
+		r.keys = r.keypad.Keys()
+		r.sampled = true
+	}
+	return r.keys
+}
+
+// Frame records the keys of the frame which has just ended.
+func (r *MovieRecorder) Frame(ip *Interpreter) {
+	r.movie.Keys = append(r.movie.Keys, r.Keys())
+	r.sampled = false
+}
+
+// MoviePlayer is a Keypad which plays back the keys recorded in a movie, one frame at a time.
+// Frame must be called at the end of every frame, by passing it to SetFrameFunc.
+// After the last frame of the movie, no keys are held down.
+//
+// A MoviePlayer is safe to use from multiple goroutines.
+type MoviePlayer struct {
+	movie *Movie
+
+	mu    sync.Mutex
+	frame int
+}
+
+// NewMoviePlayer returns a player which plays back movie from the first frame.
+func NewMoviePlayer(movie *Movie) *MoviePlayer {
+	return &MoviePlayer{movie: movie}
+}
+
+// Keys returns the keys held down in the current frame.
+func (p *MoviePlayer) Keys() uint16 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.frame >= len(p.movie.Keys) {
+		return 0
+	}
+	return p.movie.Keys[p.frame]
+}
+
+// Frame moves on to the next frame.
+func (p *MoviePlayer) Frame(ip *Interpreter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.frame++
+}
+
+// Done reports whether every frame of the movie has been played.
+func (p *MoviePlayer) Done() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.frame >= len(p.movie.Keys)
+}
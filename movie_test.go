@@ -0,0 +1,133 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// movieProgram adds random numbers and the keys pressed to V3.
+var movieProgram = []byte{
+	0xC0, 0xFF, // RND V0, 0xFF
+	0x83, 0x04, // ADD V3, V0
+	0xF1, 0x0A, // LD V1, K
+	0x83, 0x14, // ADD V3, V1
+	0x12, 0x00, // JP 0x200
+}
+
+func TestMovie(t *testing.T) {
+	const frames = 60
+	movie := &Movie{
+		ROMHash:              ROMHash(movieProgram),
+		LoadAddress:          memoryOffsetProgram,
+		Quirks:               QuirksCOSMACVIP,
+		InstructionsPerFrame: 10,
+		Seed:                 42,
+	}
+	// script holds down the keys from each frame onwards
+	script := map[int]keys{10: 1 << 5, 15: 0, 30: 1 << 7, 35: 0}
+	held := new(keys)
+	recorder := NewMovieRecorder(movie, held)
+	var frame int
+	ip := New(recorder, nil, movie.Quirks)
+	ip.SetSeed(movie.Seed)
+	ip.SetInstructionsPerFrame(movie.InstructionsPerFrame)
+	ip.SetFrameFunc(func(ip *Interpreter) {
+		recorder.Frame(ip)
+		frame++
+		if k, ok := script[frame]; ok {
+			*held = k
+		}
+	})
+	ip.LoadAt(movieProgram, movie.LoadAddress)
+	for i := 0; i < frames; i++ {
+		if err := ip.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(movie.Keys) != frames {
+		t.Fatalf("recorded %d frames, want %d", len(movie.Keys), frames)
+	}
+
+	data, err := movie.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Movie
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(movie, &decoded); diff != "" {
+		t.Fatalf("decoded movie differs (- recorded, + decoded):\n%s", diff)
+	}
+	if err := decoded.Check(movieProgram); err != nil {
+		t.Fatal(err)
+	}
+
+	player := NewMoviePlayer(&decoded)
+	replay := New(player, nil, decoded.Quirks)
+	replay.SetSeed(decoded.Seed)
+	replay.SetInstructionsPerFrame(decoded.InstructionsPerFrame)
+	replay.SetFrameFunc(player.Frame)
+	replay.LoadAt(movieProgram, decoded.LoadAddress)
+	for !player.Done() {
+		if err := replay.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff(ip.Snapshot(), replay.Snapshot()); diff != "" {
+		t.Errorf("replay differs (- recorded, + replayed):\n%s", diff)
+	}
+}
+
+func TestMovie_Check(t *testing.T) {
+	movie := Movie{ROMHash: ROMHash(movieProgram)}
+	if err := movie.Check(movieProgram[:2]); err != ErrMovieROM {
+		t.Errorf("want = %v, got = %v", ErrMovieROM, err)
+	}
+}
+
+func TestMovie_UnmarshalBinary(t *testing.T) {
+	data, err := (&Movie{Keys: []uint16{1, 2}}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{
+			name: "valid",
+			data: data,
+		},
+		{
+			name: "bad magic",
+			data: append([]byte("XXXX"), data[4:]...),
+			want: ErrMovieFormat,
+		},
+		{
+			name: "unsupported version",
+			data: append([]byte("C8MV\xFF\xFF"), data[6:]...),
+			want: ErrMovieVersion,
+		},
+		{
+			name: "missing frames",
+			data: data[:len(data)-2],
+			want: ErrMovieFormat,
+		},
+		{
+			name: "trailing data",
+			data: append(append([]byte{}, data...), 0),
+			want: ErrMovieFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Movie
+			if err := m.UnmarshalBinary(tt.data); err != tt.want {
+				t.Errorf("want = %v, got = %v", tt.want, err)
+			}
+		})
+	}
+}
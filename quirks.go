@@ -0,0 +1,81 @@
+package chip8
+
+// Quirks configures the behaviour of instructions which were implemented differently by different Chip-8 interpreters.
+//
+// Programs written for one interpreter often rely on its particular behaviour, so the quirks should be chosen to match
+// the interpreter a program was written for.
+type Quirks struct {
+	// ShiftUsesVy makes 8xy6 and 8xyE shift Vy and store the result in Vx, instead of shifting Vx in place.
+	ShiftUsesVy bool
+
+	// LoadStoreIncrementsI makes Fx55 and Fx65 leave I pointing at the address after the last register stored or loaded.
+	LoadStoreIncrementsI bool
+
+	// JumpUsesVx makes Bnnn behave as Bxnn, jumping to xnn plus Vx instead of nnn plus V0.
+	JumpUsesVx bool
+
+	// ClipSprites makes DRW clip sprites at the edges of the display instead of wrapping them around to the opposite side.
+	// The starting coordinates of a sprite always wrap.
+	ClipSprites bool
+
+	// VFReset makes 8xy1, 8xy2 and 8xy3 set VF to 0.
+	VFReset bool
+
+	// DisplayWait makes DRW wait for the next 60 Hz display refresh before execution continues.
+	DisplayWait bool
+
+	// MemorySize is the number of bytes of memory, which limits the size of programs accepted by Load.
+	// If it is zero, the 4 KB of the COSMAC VIP is used. It cannot be more than 64 KB.
+	MemorySize int
+}
+
+// Memory sizes: the 4 KB of the COSMAC VIP, which SUPER-CHIP also has, and the 64 KB of XO-CHIP.
+const (
+	defaultMemorySize = 0x1000
+	maxMemorySize     = 0x10000
+)
+
+// memorySize returns the number of bytes of memory.
+func (q Quirks) memorySize() int {
+	switch {
+	case q.MemorySize <= 0:
+		return defaultMemorySize
+	case q.MemorySize > maxMemorySize:
+		return maxMemorySize
+	}
+	return q.MemorySize
+}
+
+// Quirks presets for common Chip-8 interpreters.
+var (
+	// QuirksCOSMACVIP matches the original Chip-8 interpreter for the COSMAC VIP.
+	QuirksCOSMACVIP = Quirks{
+		ShiftUsesVy:          true,
+		LoadStoreIncrementsI: true,
+		ClipSprites:          true,
+		VFReset:              true,
+		DisplayWait:          true,
+	}
+
+	// QuirksCHIP48 matches the CHIP-48 interpreter for the HP-48 calculators.
+	//
+	// CHIP-48 actually increments I by x instead of x + 1 in Fx55 and Fx65, which is not supported.
+	QuirksCHIP48 = Quirks{
+		LoadStoreIncrementsI: true,
+		JumpUsesVx:           true,
+		ClipSprites:          true,
+	}
+
+	// QuirksSUPERCHIP matches the SUPER-CHIP 1.1 interpreter for the HP-48 calculators.
+	QuirksSUPERCHIP = Quirks{
+		JumpUsesVx:  true,
+		ClipSprites: true,
+	}
+
+	// QuirksXOCHIP matches the XO-CHIP extension as implemented by Octo, which has 64 KB of memory.
+	QuirksXOCHIP = Quirks{
+		ShiftUsesVy:          true,
+		LoadStoreIncrementsI: true,
+		MemorySize:           maxMemorySize,
+	}
+)
@@ -0,0 +1,111 @@
+package chip8
+
+// Random is a source of the random bytes generated by RND.
+type Random interface {
+	// Byte returns the next random byte. ip is the interpreter executing RND, for sources which depend on its state.
+	Byte(ip *Interpreter) uint8
+}
+
+// SavableRandom is a Random whose state is a single number, which is saved in snapshots so that a restored
+// program generates the same bytes as it did after the snapshot was taken.
+type SavableRandom interface {
+	Random
+
+	// State returns the state of the source.
+	State() uint64
+
+	// SetState restores a state returned by State.
+	SetState(state uint64)
+}
+
+// SeededRandom generates random bytes with the SplitMix64 pseudo-random number generator,
+// so that the same seed always generates the same bytes.
+type SeededRandom struct {
+	state uint64
+}
+
+// NewSeededRandom returns a pseudo-random source seeded with seed.
+func NewSeededRandom(seed int64) *SeededRandom {
+	return &SeededRandom{state: uint64(seed)}
+}
+
+func (r *SeededRandom) Byte(ip *Interpreter) uint8 {
+	r.state += 0x9E3779B97F4A7C15
+	z := r.state
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	z ^= z >> 31
+	return uint8(z >> 56)
+}
+
+func (r *SeededRandom) State() uint64 {
+	return r.state
+}
+
+func (r *SeededRandom) SetState(state uint64) {
+	r.state = state
+}
+
+// FixedRandom returns a fixed sequence of bytes, starting again from the first byte after the last.
+// It is useful for testing programs which use RND. An empty sequence always returns 0.
+type FixedRandom struct {
+	bytes []uint8
+	next  int
+}
+
+// NewFixedRandom returns a source which returns bytes in order.
+func NewFixedRandom(bytes ...uint8) *FixedRandom {
+	return &FixedRandom{bytes: bytes}
+}
+
+func (r *FixedRandom) Byte(ip *Interpreter) uint8 {
+	if len(r.bytes) == 0 {
+		return 0
+	}
+	b := r.bytes[r.next]
+	r.next = (r.next + 1) % len(r.bytes)
+	return b
+}
+
+func (r *FixedRandom) State() uint64 {
+	return uint64(r.next)
+}
+
+func (r *FixedRandom) SetState(state uint64) {
+	if len(r.bytes) > 0 {
+		r.next = int(state % uint64(len(r.bytes)))
+	}
+}
+
+// vipRandomPage is the page of memory read by VIPRandom.
+const vipRandomPage = memoryOffsetProgram
+
+// VIPRandom emulates the RND of the original COSMAC VIP interpreter, which did not use a random number generator
+// but walked a pointer through memory.
+//
+// The VIP kept a 16-bit value in a register. For each RND, it incremented the value, used the low byte as a pointer
+// into a page of memory, and added the byte found there to the high byte, which became both the high byte of the
+// value and the random number.
+//
+// The VIP read its own interpreter, which is not in memory here, so the first page of the program at 0x200 is read
+// instead. The bytes generated are not the same as on a VIP, but behave the same way: they are determined by the
+// program and the number of RND instructions executed so far, and are poorly distributed if the page is mostly empty.
+type VIPRandom struct {
+	value uint16
+}
+
+func (r *VIPRandom) Byte(ip *Interpreter) uint8 {
+	r.value++
+	lo, hi := uint8(r.value), uint8(r.value>>8)
+	b := hi + ip.memory[vipRandomPage+uint16(lo)]
+	r.value = uint16(b)<<8 | uint16(lo)
+	return b
+}
+
+func (r *VIPRandom) State() uint64 {
+	return uint64(r.value)
+}
+
+func (r *VIPRandom) SetState(state uint64) {
+	r.value = uint16(state)
+}
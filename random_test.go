@@ -0,0 +1,86 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// randomBytes returns the first n bytes generated by r for ip.
+func randomBytes(r Random, ip *Interpreter, n int) []uint8 {
+	b := make([]uint8, n)
+	for i := range b {
+		b[i] = r.Byte(ip)
+	}
+	return b
+}
+
+func TestSeededRandom(t *testing.T) {
+	want := randomBytes(NewSeededRandom(1), nil, 16)
+	if got := randomBytes(NewSeededRandom(1), nil, 16); !cmp.Equal(want, got) {
+		t.Errorf("same seed: want = %X, got = %X", want, got)
+	}
+	if got := randomBytes(NewSeededRandom(2), nil, 16); cmp.Equal(want, got) {
+		t.Errorf("different seeds generated the same bytes %X", got)
+	}
+}
+
+func TestFixedRandom(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []uint8
+		want  []uint8
+	}{
+		{
+			name: "empty",
+			want: []uint8{0, 0, 0},
+		},
+		{
+			name:  "repeats",
+			bytes: []uint8{1, 2},
+			want:  []uint8{1, 2, 1, 2, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := randomBytes(NewFixedRandom(tt.bytes...), nil, len(tt.want))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestVIPRandom(t *testing.T) {
+	ip := New(nil, nil, QuirksCOSMACVIP)
+	ip.Load([]byte{0x00, 0x10, 0x20, 0x30})
+	// each byte is the previous byte plus the next byte of the program
+	want := []uint8{0x10, 0x30, 0x60, 0x60}
+	if diff := cmp.Diff(want, randomBytes(new(VIPRandom), ip, len(want))); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSavableRandom(t *testing.T) {
+	ip := New(nil, nil, QuirksCOSMACVIP)
+	ip.Load([]byte{0x00, 0x10, 0x20, 0x30})
+	tests := []struct {
+		name string
+		r    SavableRandom
+	}{
+		{"SeededRandom", NewSeededRandom(1)},
+		{"FixedRandom", NewFixedRandom(1, 2, 3)},
+		{"VIPRandom", new(VIPRandom)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			randomBytes(tt.r, ip, 2)
+			state := tt.r.State()
+			want := randomBytes(tt.r, ip, 8)
+			tt.r.SetState(state)
+			if diff := cmp.Diff(want, randomBytes(tt.r, ip, 8)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package chip8
+
+// Rewinder records the state of an interpreter every frame so that it can be rewound one frame at a time.
+//
+// To keep memory use small, only the most recent state is kept in full. For every earlier frame, the Rewinder
+// keeps only the parts of its serialized snapshot which differ from the frame after it, which for most programs
+// is a handful of registers and the few bytes of memory and display that changed.
+type Rewinder struct {
+	// cur is the serialized snapshot of the most recently recorded frame.
+	cur []byte
+
+	// history is a ring buffer of the deltas needed to go back one frame, with the most recent at the end.
+	history []delta
+	start   int
+	n       int
+}
+
+// span is a run of bytes at an offset in a serialized snapshot.
+type span struct {
+	offset int
+	data   []byte
+}
+
+// delta contains the bytes of a serialized snapshot which changed between two frames, as they were
+// in the earlier frame.
+type delta []span
+
+// maxSpanGap is the largest run of unchanged bytes which is included in a span to avoid starting a new one.
+const maxSpanGap = 8
+
+// diff returns the delta which turns next back into prev. prev and next must have the same length.
+func diff(prev, next []byte) delta {
+	var d delta
+	for i := 0; i < len(prev); i++ {
+		if prev[i] == next[i] {
+			continue
+		}
+		start, end := i, i+1
+		for j := end; j < len(prev) && j < end+maxSpanGap; j++ {
+			if prev[j] != next[j] {
+				end = j + 1
+			}
+		}
+		d = append(d, span{
+			offset: start,
+			data:   append([]byte(nil), prev[start:end]...),
+		})
+		i = end - 1
+	}
+	return d
+}
+
+// apply overwrites the bytes in b which changed with their earlier values.
+func (d delta) apply(b []byte) {
+	for _, s := range d {
+		copy(b[s.offset:], s.data)
+	}
+}
+
+// NewRewinder returns a Rewinder which can rewind up to frames frames.
+func NewRewinder(frames int) *Rewinder {
+	return &Rewinder{
+		history: make([]delta, frames),
+	}
+}
+
+// Record records the current state of ip. It should be called after every frame.
+func (r *Rewinder) Record(ip *Interpreter) error {
+	next, err := ip.Snapshot().MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if r.cur != nil && len(r.history) > 0 {
+		d := diff(r.cur, next)
+		if r.n == len(r.history) {
+			// discard the oldest frame
+			r.start = (r.start + 1) % len(r.history)
+			r.n--
+		}
+		r.history[(r.start+r.n)%len(r.history)] = d
+		r.n++
+	}
+	r.cur = next
+	return nil
+}
+
+// Rewind restores ip to the state it was in at the frame before the most recently recorded one,
+// which then becomes the most recently recorded frame. It reports whether there was a frame to rewind to.
+func (r *Rewinder) Rewind(ip *Interpreter) (bool, error) {
+	if r.n == 0 {
+		return false, nil
+	}
+	r.n--
+	i := (r.start + r.n) % len(r.history)
+	r.history[i].apply(r.cur)
+	r.history[i] = nil
+
+	var s Snapshot
+	if err := s.UnmarshalBinary(r.cur); err != nil {
+		return false, err
+	}
+	ip.Restore(&s)
+	return true, nil
+}
+
+// Len returns the number of frames that can currently be rewound.
+func (r *Rewinder) Len() int {
+	return r.n
+}
+
+// Reset discards all recorded frames.
+func (r *Rewinder) Reset() {
+	r.cur = nil
+	for i := range r.history {
+		r.history[i] = nil
+	}
+	r.start, r.n = 0, 0
+}
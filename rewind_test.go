@@ -0,0 +1,74 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRewinder(t *testing.T) {
+	ip := New(nil, nil, QuirksXOCHIP)
+	ip.SetInstructionsPerFrame(1)
+	ip.Load([]byte{
+		0xA2, 0x00, // LD I, 0x200
+		0x70, 0x01, // ADD V0, 0x01
+		0xF0, 0x33, // LD B, V0
+		0xD0, 0x03, // DRW V0, V0, 3
+		0x12, 0x02, // JP 0x202
+	})
+
+	r := NewRewinder(8)
+	var snapshots []*Snapshot
+	if err := r.Record(ip); err != nil {
+		t.Fatal(err)
+	}
+	snapshots = append(snapshots, ip.Snapshot())
+	for i := 0; i < 20; i++ {
+		if err := ip.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Record(ip); err != nil {
+			t.Fatal(err)
+		}
+		snapshots = append(snapshots, ip.Snapshot())
+	}
+
+	if r.Len() != 8 {
+		t.Fatalf("Len: want = %d, got = %d", 8, r.Len())
+	}
+	for i := len(snapshots) - 2; i >= len(snapshots)-9; i-- {
+		ok, err := r.Rewind(ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("frame %d: want rewound", i)
+		}
+		if diff := cmp.Diff(snapshots[i], ip.Snapshot()); diff != "" {
+			t.Fatalf("frame %d: %s", i, diff)
+		}
+	}
+	if ok, _ := r.Rewind(ip); ok {
+		t.Error("want no more frames to rewind")
+	}
+}
+
+func Test_diff(t *testing.T) {
+	prev := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}
+	next := append([]byte(nil), prev...)
+	next[1] = 0xFF
+	next[3] = 0xFF
+	next[19] = 0xFF
+	d := diff(prev, next)
+	want := delta{
+		{offset: 1, data: []byte{1, 2, 3}},
+		{offset: 19, data: []byte{19}},
+	}
+	if diff := cmp.Diff(want, d, cmp.AllowUnexported(span{})); diff != "" {
+		t.Error(diff)
+	}
+	d.apply(next)
+	if diff := cmp.Diff(prev, next); diff != "" {
+		t.Error(diff)
+	}
+}
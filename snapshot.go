@@ -0,0 +1,180 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// snapshotMagic identifies a serialized snapshot.
+var snapshotMagic = [4]byte{'C', '8', 'S', 'S'}
+
+// SnapshotVersion is the version of the snapshot format written by Snapshot.MarshalBinary.
+const SnapshotVersion = 1
+
+// Errors returned when decoding snapshots.
+var (
+	ErrSnapshotFormat  = errors.New("chip8: not a snapshot")
+	ErrSnapshotVersion = errors.New("chip8: unsupported snapshot version")
+)
+
+// Snapshot is a copy of the complete state of a running program.
+//
+// A snapshot is serialized as a big-endian binary encoding of its fields in the order they are declared,
+// preceded by the 4 bytes "C8SS" and a 2-byte format version.
+type Snapshot struct {
+	Memory    [65536]uint8
+	Registers [16]uint8
+	I         uint16
+	PC        uint16
+	SP        uint8
+	Stack     [16]uint16
+	DT        uint8
+	ST        uint8
+	Display   Display
+
+	// SUPER-CHIP and XO-CHIP state
+	Flags   [16]uint8
+	Plane   uint8
+	Pattern [16]uint8
+	Pitch   uint8
+	Exited  bool
+
+	// KeyWait is set while Fx0A waits for WaitKey to be released.
+	KeyWait bool
+	WaitKey uint8
+
+	// RandomState is the state of the random source of RND, if it is a SavableRandom.
+	// Restore only sets it if the interpreter being restored has a SavableRandom source.
+	RandomState uint64
+}
+
+type snapshotHeader struct {
+	Magic   [4]byte
+	Version uint16
+}
+
+// Snapshot returns a snapshot of the current state of the interpreter.
+// It must not be called while Run is executing.
+func (ip *Interpreter) Snapshot() *Snapshot {
+	s := &Snapshot{
+		Memory:    ip.memory,
+		Registers: ip.registers,
+		I:         ip.i,
+		PC:        ip.pc,
+		SP:        ip.sp,
+		Stack:     ip.stack,
+		DT:        ip.dt,
+		ST:        ip.st,
+		Display:   ip.display,
+		Flags:     ip.flags,
+		Plane:     ip.plane,
+		Pattern:   ip.pattern,
+		Pitch:     ip.pitch,
+		Exited:    ip.exited,
+		KeyWait:   ip.keyWait,
+		WaitKey:   ip.waitKey,
+	}
+	if r, ok := ip.random.(SavableRandom); ok {
+		s.RandomState = r.State()
+	}
+	return s
+}
+
+// Restore restores the state of the interpreter from s, and clears any fault that stopped it.
+// It must not be called while Run is executing.
+func (ip *Interpreter) Restore(s *Snapshot) {
+	ip.memory = s.Memory
+	ip.registers = s.Registers
+	ip.i = s.I
+	ip.pc = s.PC
+	ip.sp = s.SP
+	ip.stack = s.Stack
+	ip.dt = s.DT
+	ip.st = s.ST
+	ip.display = s.Display
+	ip.flags = s.Flags
+	ip.plane = s.Plane
+	ip.pattern = s.Pattern
+	ip.pitch = s.Pitch
+	ip.exited = s.Exited
+	ip.keyWait = s.KeyWait
+	ip.waitKey = s.WaitKey
+	if r, ok := ip.random.(SavableRandom); ok {
+		r.SetState(s.RandomState)
+	}
+	ip.vblankWait = false
+	ip.err = nil
+	ip.render()
+	ip.updateSound()
+}
+
+// fields returns pointers to the fields of the snapshot in the order they are serialized.
+// Arrays are returned as slices so that they can be encoded and decoded without reflection.
+func (s *Snapshot) fields() []interface{} {
+	fields := []interface{}{
+		s.Memory[:],
+		s.Registers[:],
+		&s.I,
+		&s.PC,
+		&s.SP,
+		s.Stack[:],
+		&s.DT,
+		&s.ST,
+		&s.Display.Hires,
+	}
+	for p := range s.Display.Planes {
+		for y := range s.Display.Planes[p] {
+			fields = append(fields, s.Display.Planes[p][y][:])
+		}
+	}
+	return append(fields,
+		s.Flags[:],
+		&s.Plane,
+		s.Pattern[:],
+		&s.Pitch,
+		&s.Exited,
+		&s.KeyWait,
+		&s.WaitKey,
+		&s.RandomState,
+	)
+}
+
+// MarshalBinary encodes the snapshot in the current snapshot format.
+func (s *Snapshot) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	header := snapshotHeader{
+		Magic:   snapshotMagic,
+		Version: SnapshotVersion,
+	}
+	if err := binary.Write(&buf, binary.BigEndian, header); err != nil {
+		return nil, err
+	}
+	for _, field := range s.fields() {
+		if err := binary.Write(&buf, binary.BigEndian, field); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a snapshot encoded by MarshalBinary.
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	var header snapshotHeader
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil || header.Magic != snapshotMagic {
+		return ErrSnapshotFormat
+	}
+	if header.Version != SnapshotVersion {
+		return ErrSnapshotVersion
+	}
+	for _, field := range s.fields() {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return ErrSnapshotFormat
+		}
+	}
+	if r.Len() > 0 {
+		return ErrSnapshotFormat
+	}
+	return nil
+}
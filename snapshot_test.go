@@ -0,0 +1,132 @@
+package chip8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnapshot_MarshalBinary(t *testing.T) {
+	ip := New(nil, nil, QuirksXOCHIP)
+	ip.Load([]byte{
+		0x00, 0xFF, // HIGH
+		0x60, 0x3C, // LD V0, 0x3C
+		0xF0, 0x15, // LD DT, V0
+		0xA2, 0x00, // LD I, 0x200
+		0xD0, 0x05, // DRW V0, V0, 5
+		0x22, 0x0E, // CALL 0x20E
+		0x00, 0x00,
+		0x12, 0x0E, // JP 0x20E
+	})
+	for i := 0; i < 6; i++ {
+		if err := ip.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := ip.Snapshot().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Snapshot
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	restored := New(nil, nil, QuirksXOCHIP)
+	restored.Restore(&s)
+	if diff := cmp.Diff(ip.Snapshot(), restored.Snapshot()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSnapshot_Restore_replay(t *testing.T) {
+	keyboard := NewKeyboard(nil)
+	ip := New(keyboard, nil, Quirks{})
+	ip.SetSeed(7)
+	ip.SetInstructionsPerFrame(4)
+	ip.Load([]byte{
+		0xC1, 0xFF, // RND V1, 0xFF
+		0xF0, 0x0A, // LD V0, K
+		0xC2, 0xFF, // RND V2, 0xFF
+		0x12, 0x04, // JP 0x204
+	})
+	keyboard.PressKeys(1 << 5)
+	if err := ip.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+	// Fx0A has seen key 5 pressed, and is waiting for it to be released
+	data, err := ip.Snapshot().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func(ip *Interpreter) [16]uint8 {
+		for i := 0; i < 3; i++ {
+			if err := ip.RunFrame(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ip.Registers()
+	}
+	keyboard.ReleaseKeys(1 << 5)
+	want := run(ip)
+
+	var s Snapshot
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	restored := New(keyboard, nil, Quirks{})
+	restored.SetInstructionsPerFrame(4)
+	// the seed is replaced by the state of the generator in the snapshot
+	restored.SetSeed(0)
+	restored.Restore(&s)
+	if diff := cmp.Diff(want, run(restored)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSnapshot_UnmarshalBinary(t *testing.T) {
+	data, err := new(Snapshot).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{
+			name: "valid",
+			data: data,
+		},
+		{
+			name: "bad magic",
+			data: append([]byte("XXXX"), data[4:]...),
+			want: ErrSnapshotFormat,
+		},
+		{
+			name: "unsupported version",
+			data: append([]byte("C8SS\xFF\xFF"), data[6:]...),
+			want: ErrSnapshotVersion,
+		},
+		{
+			name: "truncated",
+			data: data[:len(data)-1],
+			want: ErrSnapshotFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Snapshot
+			if err := s.UnmarshalBinary(tt.data); err != tt.want {
+				t.Errorf("want = %v, got = %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Snapshot_noRandom(t *testing.T) {
+	ip := New(nil, nil, Quirks{})
+	if s := ip.Snapshot(); s.RandomState != 0 || ip.random != nil {
+		t.Errorf("Snapshot seeded a random source: state = %X, source = %v", s.RandomState, ip.random)
+	}
+}
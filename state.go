@@ -0,0 +1,54 @@
+package chip8
+
+// The following methods inspect the current state of the interpreter.
+// They must not be called while Run is executing.
+
+// Registers returns the values of the general purpose registers V0 through VF.
+func (ip *Interpreter) Registers() [16]uint8 {
+	return ip.registers
+}
+
+// I returns the value of the I register.
+func (ip *Interpreter) I() uint16 {
+	return ip.i
+}
+
+// PC returns the value of the program counter.
+func (ip *Interpreter) PC() uint16 {
+	return ip.pc
+}
+
+// SP returns the value of the stack pointer, which is the number of addresses on the stack.
+func (ip *Interpreter) SP() uint8 {
+	return ip.sp
+}
+
+// Stack returns the contents of the stack. Only the first SP addresses are in use.
+func (ip *Interpreter) Stack() [16]uint16 {
+	return ip.stack
+}
+
+// DT returns the value of the delay timer.
+func (ip *Interpreter) DT() uint8 {
+	return ip.dt
+}
+
+// ST returns the value of the sound timer.
+func (ip *Interpreter) ST() uint8 {
+	return ip.st
+}
+
+// Memory returns the interpreter's memory. The returned slice must not be modified.
+func (ip *Interpreter) Memory() []uint8 {
+	return ip.memory[:]
+}
+
+// Display returns the current contents of the display.
+func (ip *Interpreter) Display() Display {
+	return ip.display
+}
+
+// Exited reports whether the program has executed the SUPER-CHIP EXIT instruction.
+func (ip *Interpreter) Exited() bool {
+	return ip.exited
+}
@@ -1,4 +1,4 @@
-package main
+package chip8
 
 import (
 	"time"
@@ -7,27 +7,13 @@ import (
 // The Chip-8 timers run at 60 Hz
 const timerInterval = time.Second / 60
 
-func newTimer() (<-chan uint8, chan<- uint8, chan<- struct{}) {
-	getch := make(chan uint8)
-	setch := make(chan uint8)
-	stopch := make(chan struct{})
-	go func() {
-		var val uint8
-		ticker := time.NewTicker(timerInterval)
-		defer ticker.Stop()
-		for {
-			select {
-			case getch <- val:
-			case v := <-setch:
-				val = v
-			case <-ticker.C:
-				if val > 0 {
-					val--
-				}
-			case <-stopch:
-				return
-			}
-		}
-	}()
-	return getch, setch, stopch
+// tickTimers decrements the delay and sound timers if they are not already zero.
+func (ip *Interpreter) tickTimers() {
+	if ip.dt > 0 {
+		ip.dt--
+	}
+	if ip.st > 0 {
+		ip.st--
+	}
+	ip.updateSound()
 }